@@ -0,0 +1,184 @@
+package htxp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Claims 统一的基础声明，htxp 签发与 htxp/middleware 解析共用
+// 业务声明通过嵌入扩展：
+//
+//	type ShopClaims struct {
+//		htxp.Claims
+//		ShopID int64 `json:"shop_id"`
+//	}
+type Claims struct {
+	UserID        int64          `json:"user_id"`
+	Version       int64          `json:"version"`                  // Token版本号，用于权限变更时使旧Token失效
+	DeviceID      string         `json:"device_id,omitempty"`      // 设备ID（可选，用于按设备注销）
+	DeviceVersion int64          `json:"device_version,omitempty"` // 设备Token版本号
+	Roles         []string       `json:"roles,omitempty"`          // 角色列表（可选，减少RPC调用）
+	TenantID      string         `json:"tenant_id,omitempty"`      // 租户ID（多租户隔离）
+	Act           *Impersonation `json:"act,omitempty"`            // 模拟登录时的实际操作人
+	KeepID        bool           `json:"-"`                        // 签发时保留已设置的 jti（默认每次签发生成新的 jti）
+	jwt.RegisteredClaims
+}
+
+// Impersonation 模拟登录信息（写入Token的 act 声明）
+type Impersonation struct {
+	ActorID int64  `json:"actor_id"`         // 实际操作人（客服/管理员）用户ID
+	Reason  string `json:"reason,omitempty"` // 模拟登录原因（如工单号）
+}
+
+// TokenClaims 可用于 GenTokenFor / ParseTokenAs 的声明类型（嵌入 Claims 的结构体指针）
+type TokenClaims interface {
+	jwt.Claims
+	BaseClaims() *Claims
+}
+
+// BaseClaims 返回基础声明
+func (c *Claims) BaseClaims() *Claims {
+	return c
+}
+
+// Stamp 写入签发时间、过期时间、新的 jti（KeepID 为 true 且已设置时保留），以及默认的签发方与受众（已存在时不覆盖）
+// 复用同一声明多次签发时每个Token的 jti 各不相同，吊销其中一个不会影响其他Token
+func (c *Claims) Stamp(iat, seconds int64) {
+	if c.ID == "" || !c.KeepID {
+		c.ID = NewTokenID()
+	}
+	if c.Issuer == "" {
+		c.Issuer = DefaultTokenOptions.Issuer
+	}
+	if len(c.Audience) == 0 {
+		c.Audience = DefaultTokenOptions.Audience
+	}
+	c.IssuedAt = jwt.NewNumericDate(time.Unix(iat, 0))
+	c.ExpiresAt = jwt.NewNumericDate(time.Unix(iat+seconds, 0))
+}
+
+// GenTokenFor 使用共享密钥签发类型化声明的jwt（HS256）
+func GenTokenFor[T TokenClaims](secretKey string, iat, seconds int64, claims T) (string, error) {
+	claims.BaseClaims().Stamp(iat, seconds)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+}
+
+// GenTokenForKeySet 使用密钥集中的当前密钥签发类型化声明的jwt（写入 kid 头）
+func GenTokenForKeySet[T TokenClaims](ks *KeySet, iat, seconds int64, claims T) (string, error) {
+	key, err := ks.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	claims.BaseClaims().Stamp(iat, seconds)
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// ParseTokenAs 使用共享密钥解析类型化声明的jwt，如 htxp.ParseTokenAs[ShopClaims](token, secret)；密钥为空时拒绝所有Token
+func ParseTokenAs[T any, PT interface {
+	*T
+	TokenClaims
+}](tokenString, secret string) (PT, error) {
+	return ParseTokenAsWithKeyfunc[T, PT](tokenString, hmacKeyfunc(secret))
+}
+
+// ParseTokenAsWithKeyfunc 使用自定义 Keyfunc 解析类型化声明的jwt（如 KeySet.Keyfunc）
+func ParseTokenAsWithKeyfunc[T any, PT interface {
+	*T
+	TokenClaims
+}](tokenString string, keyFunc jwt.Keyfunc) (PT, error) {
+	claims := PT(new(T))
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return claims, nil
+}
+
+// ParseLegacyToken 解析旧版Token（GenTokenWithPayload / GenTokenWithUser 签发）并转换为 Claims
+// 用户ID依次取 user_id、uid、payload；租户ID依次取 tenant_id、cid；
+// 旧版Token不含版本号，按默认版本号1处理，用户版本号递增后即失效
+func ParseLegacyToken(tokenString, secret string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, mapClaims, hmacKeyfunc(secret))
+	if err != nil {
+		return nil, err
+	}
+	return LegacyClaims(mapClaims)
+}
+
+// hmacKeyfunc 共享密钥的 jwt.Keyfunc，仅接受HMAC签名算法；密钥为空时拒绝所有Token
+func hmacKeyfunc(secret string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if secret == "" {
+			return nil, errors.New("jwt secret required")
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		return []byte(secret), nil
+	}
+}
+
+// LegacyClaims 将旧版 MapClaims 转换为 Claims
+func LegacyClaims(mapClaims jwt.MapClaims) (*Claims, error) {
+	claims := &Claims{Version: 1}
+	for _, key := range []string{"user_id", "uid", "payload"} {
+		if userID, ok := claimInt64(mapClaims[key]); ok {
+			claims.UserID = userID
+			break
+		}
+	}
+	if claims.UserID == 0 {
+		return nil, errors.New("token user id not found")
+	}
+	if version, ok := claimInt64(mapClaims["version"]); ok {
+		claims.Version = version
+	}
+	for _, key := range []string{"tenant_id", "cid"} {
+		if v, ok := mapClaims[key]; ok && v != nil {
+			claims.TenantID = fmt.Sprint(v)
+			break
+		}
+	}
+	if roles, ok := mapClaims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if s, ok := role.(string); ok {
+				claims.Roles = append(claims.Roles, s)
+			}
+		}
+	}
+	claims.ID, _ = mapClaims["jti"].(string)
+	claims.Issuer, _ = mapClaims["iss"].(string)
+	if exp, ok := claimInt64(mapClaims["exp"]); ok {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(exp, 0))
+	}
+	if iat, ok := claimInt64(mapClaims["iat"]); ok {
+		claims.IssuedAt = jwt.NewNumericDate(time.Unix(iat, 0))
+	}
+	return claims, nil
+}
+
+// claimInt64 将JSON数字或数字字符串转换为 int64
+func claimInt64(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return int64(val), true
+	case int64:
+		return val, true
+	case int:
+		return int64(val), true
+	case string:
+		i, err := strconv.ParseInt(val, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
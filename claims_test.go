@@ -0,0 +1,23 @@
+package htxp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimsStampTokenID(t *testing.T) {
+	iat := time.Now().Unix()
+	claims := &Claims{UserID: 42}
+	claims.Stamp(iat, 60)
+	first := claims.ID
+	claims.Stamp(iat, 60)
+	if first == "" || claims.ID == first {
+		t.Fatalf("reused claims kept jti %q", first)
+	}
+
+	claims.ID, claims.KeepID = "fixed", true
+	claims.Stamp(iat, 60)
+	if claims.ID != "fixed" {
+		t.Fatalf("KeepID: jti = %q", claims.ID)
+	}
+}
@@ -0,0 +1,131 @@
+package htxp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CodedError 携带业务码、HTTP状态码与消息键的错误
+// 业务逻辑中直接返回（或使用 %w 包装），Response 通过 errors.As 找到后渲染对应的 Body
+type CodedError struct {
+	Code    int                    // 业务码（写入 Body.Code）
+	Status  int                    // HTTP状态码
+	Key     string                 // 消息键（在 DefaultCatalog 中查找多语言消息）
+	Msg     string                 // 默认消息（未确定语言或目录中无该键时使用），内置错误统一为英文
+	Args    map[string]interface{} // 消息占位符参数，如 {"id": 1} 替换 {id}
+	Details interface{}            // 附加信息（写入 Body.Data），如字段校验错误
+	cause   error
+}
+
+var (
+	codeRegistry   = map[int]*CodedError{}
+	codeRegistryMu sync.RWMutex
+)
+
+// 通用业务码（与本库其他内置错误一样登记到注册表，业务码冲突时在启动时 panic，可用 Codes 查看已占用的业务码）
+var (
+	ErrBadRequest         = RegisterCode(400, 400, "common.bad_request", "bad request")
+	ErrUnauthorized       = RegisterCode(401, 401, "common.unauthorized", "unauthorized")
+	ErrForbidden          = RegisterCode(403, 403, "common.forbidden", "forbidden")
+	ErrNotFound           = RegisterCode(404, 404, "common.not_found", "not found")
+	ErrConflict           = RegisterCode(409, 409, "common.conflict", "conflict")
+	ErrTooManyRequests    = RegisterCode(429, 429, "common.too_many_requests", "too many requests")
+	ErrInternal           = RegisterCode(500, 500, "common.internal", "internal error")
+	ErrServiceUnavailable = RegisterCode(503, 503, "common.service_unavailable", "service unavailable")
+)
+
+// RegisterCode 注册业务码并返回对应的错误（应在包级变量或 init 中调用）
+// 以相同的状态码与消息键重复注册时返回已注册的错误，业务码冲突时 panic
+func RegisterCode(code, status int, key, msg string) *CodedError {
+	codeRegistryMu.Lock()
+	defer codeRegistryMu.Unlock()
+	if exist, ok := codeRegistry[code]; ok {
+		if exist.Status == status && exist.Key == key {
+			return exist
+		}
+		panic(fmt.Sprintf("htxp: code %d already registered as %q", code, exist.Key))
+	}
+	e := &CodedError{Code: code, Status: status, Key: key, Msg: msg}
+	codeRegistry[code] = e
+	return e
+}
+
+// NewCodedError 创建不登记到注册表的错误（如临时构造的错误），不参与业务码冲突检测
+func NewCodedError(code, status int, key, msg string) *CodedError {
+	return &CodedError{Code: code, Status: status, Key: key, Msg: msg}
+}
+
+// LookupCode 查询已注册的业务码
+func LookupCode(code int) (*CodedError, bool) {
+	codeRegistryMu.RLock()
+	defer codeRegistryMu.RUnlock()
+	e, ok := codeRegistry[code]
+	return e, ok
+}
+
+// Codes 返回所有已注册的业务码（按业务码排序），可用于生成错误码文档
+func Codes() []*CodedError {
+	codeRegistryMu.RLock()
+	defer codeRegistryMu.RUnlock()
+	codes := make([]*CodedError, 0, len(codeRegistry))
+	for _, e := range codeRegistry {
+		codes = append(codes, e)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
+// Error 返回默认消息，包装了底层错误时一并输出（仅用于日志，不会写入响应）
+func (e *CodedError) Error() string {
+	msg := FormatMessage(e.Msg, e.Args)
+	if e.cause != nil {
+		return msg + ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Message 返回指定语言的消息，语言为空或目录中无该键时返回默认消息
+func (e *CodedError) Message(locale string) string {
+	if locale != "" && e.Key != "" {
+		if msg, ok := DefaultCatalog.Lookup(locale, e.Key); ok {
+			return FormatMessage(msg, e.Args)
+		}
+	}
+	return FormatMessage(e.Msg, e.Args)
+}
+
+// Unwrap 返回被包装的底层错误
+func (e *CodedError) Unwrap() error {
+	return e.cause
+}
+
+// Is 业务码相同即视为同一错误，WithDetails / WithArgs / Wrap 产生的副本仍可用 errors.Is 判断
+// 内置错误均使用互不相同且已注册的业务码（如 40301、40302），注册表保证业务码不会被复用
+func (e *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails 返回携带附加信息的副本
+func (e *CodedError) WithDetails(details interface{}) *CodedError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithArgs 返回携带消息占位符参数的副本
+func (e *CodedError) WithArgs(args map[string]interface{}) *CodedError {
+	c := *e
+	c.Args = args
+	return &c
+}
+
+// Wrap 返回包装了底层错误的副本，底层错误只出现在日志中
+func (e *CodedError) Wrap(cause error) *CodedError {
+	c := *e
+	c.cause = cause
+	return &c
+}
@@ -0,0 +1,50 @@
+package htxp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodedErrorIs(t *testing.T) {
+	notFound := ErrNotFound.WithArgs(map[string]interface{}{"id": 1}).Wrap(errors.New("record not found"))
+	if !errors.Is(fmt.Errorf("query: %w", notFound), ErrNotFound) {
+		t.Fatal("wrapped copy should match its sentinel")
+	}
+	if errors.Is(ErrNotFound, ErrConflict) {
+		t.Fatal("errors with different codes should not match")
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	first := RegisterCode(99001, 400, "test.duplicate", "duplicate")
+	if again := RegisterCode(99001, 400, "test.duplicate", "duplicate"); again != first {
+		t.Fatal("identical re-registration should return the registered error")
+	}
+	if e, ok := LookupCode(ErrForbidden.Code); !ok || e != ErrForbidden {
+		t.Fatal("built-in codes should be registered")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("conflicting registration should panic")
+		}
+	}()
+	RegisterCode(ErrUnauthorized.Code, 401, "biz.unauthorized", "unauthorized")
+}
+
+func TestBuiltinMessagesEnglish(t *testing.T) {
+	builtins := []*CodedError{
+		ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict,
+		ErrTooManyRequests, ErrInternal, ErrServiceUnavailable, ErrUIDNotFound,
+		ErrSmsMobilesRequired, ErrSmsParamsInvalid, ErrSmsClientFailed, ErrSmsSendFailed, ErrSmsRejected,
+	}
+	for _, e := range builtins {
+		if e.Msg != enMessages[e.Key] {
+			t.Errorf("%s: Msg = %q, want %q", e.Key, e.Msg, enMessages[e.Key])
+		}
+		if _, ok := zhCNMessages[e.Key]; !ok {
+			t.Errorf("%s: missing zh-CN message", e.Key)
+		}
+	}
+}
@@ -75,12 +75,12 @@ func main() {
 			"name": "张三",
 			"age":  30,
 		}
-		htxp.Success(w, data)
+		htxp.SuccessCtx(r.Context(), w, data)
 	})
 
 	http.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
 		err := errors.New("发生了一个错误")
-		htxp.Error(w, err)
+		htxp.ErrorCtx(r.Context(), w, err)
 	})
 
 	http.HandleFunc("/generate", func(w http.ResponseWriter, r *http.Request) {
@@ -92,7 +92,7 @@ func main() {
 			// "randomUUID": htxp.GenerateRandomUUID(),
 			"md5Hash":    htxp.Md5V("test123"),
 		}
-		htxp.Success(w, randomData)
+		htxp.SuccessCtx(r.Context(), w, randomData)
 	})
 
 	fmt.Println("服务器启动在 :8080")
@@ -0,0 +1,242 @@
+package htxp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 内置语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+type localeKey struct{}
+
+// Catalog 多语言消息目录，消息中的 {name} 占位符由参数替换
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string // locale -> key -> message
+	locales  []string                     // 按注册顺序，匹配基础语言时优先使用先注册的
+}
+
+// DefaultCatalog 默认消息目录，内置 zh-CN 与 en 消息，业务消息可通过 Register 追加
+var DefaultCatalog = NewCatalog()
+
+func init() {
+	DefaultCatalog.Register(LocaleZhCN, zhCNMessages)
+	DefaultCatalog.Register(LocaleEn, enMessages)
+}
+
+// NewCatalog 创建空的消息目录
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[string]string{}}
+}
+
+// Register 注册（合并）某个语言的消息
+func (c *Catalog) Register(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bundle, ok := c.messages[locale]
+	if !ok {
+		bundle = map[string]string{}
+		c.messages[locale] = bundle
+		c.locales = append(c.locales, locale)
+	}
+	for key, msg := range messages {
+		bundle[key] = msg
+	}
+}
+
+// Lookup 查询消息模板，语言不存在时按基础语言匹配（如 en-US -> en、zh -> zh-CN）
+func (c *Catalog) Lookup(locale, key string) (string, bool) {
+	locale = c.resolve(locale)
+	if locale == "" {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	msg, ok := c.messages[locale][key]
+	return msg, ok
+}
+
+// Translate 翻译消息并替换占位符，未找到时返回 key
+func (c *Catalog) Translate(locale, key string, args map[string]interface{}) string {
+	msg, ok := c.Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return FormatMessage(msg, args)
+}
+
+// Match 按 Accept-Language（含 q 权重）选择支持的语言，无匹配时返回空字符串
+func (c *Catalog) Match(acceptLanguage string) string {
+	type tag struct {
+		name string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name != "" && name != "*" && q > 0 {
+			tags = append(tags, tag{name: name, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	for _, t := range tags {
+		if locale := c.resolve(t.name); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// resolve 返回目录中与 locale 匹配的语言（忽略大小写，其次按基础语言匹配）
+func (c *Catalog) resolve(locale string) string {
+	if locale == "" {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locale = strings.ReplaceAll(locale, "_", "-")
+	for _, l := range c.locales {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	for _, l := range c.locales {
+		lbase, _, _ := strings.Cut(l, "-")
+		if strings.EqualFold(lbase, base) {
+			return l
+		}
+	}
+	return ""
+}
+
+// FormatMessage 将消息中的 {name} 占位符替换为参数值
+func FormatMessage(msg string, args map[string]interface{}) string {
+	if len(args) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// WithLocale 将语言写入context（如由 middleware.LocaleMiddleware 根据 Accept-Language 写入）
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext 获取context中的语言，未设置时返回空字符串
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// T 按context中的语言翻译消息，未设置语言或未找到时返回 key
+func T(ctx context.Context, key string, args map[string]interface{}) string {
+	return DefaultCatalog.Translate(LocaleFromContext(ctx), key, args)
+}
+
+// zhCNMessages 内置简体中文消息
+var zhCNMessages = map[string]string{
+	"common.bad_request":           "请求参数错误",
+	"common.unauthorized":          "未登录或登录已失效",
+	"common.forbidden":             "没有访问权限",
+	"common.not_found":             "资源不存在",
+	"common.conflict":              "资源冲突",
+	"common.too_many_requests":     "请求过于频繁，请稍后重试",
+	"common.internal":              "服务器内部错误",
+	"common.service_unavailable":   "服务暂不可用，请稍后重试",
+	"auth.token_missing":           "请先登录",
+	"auth.token_malformed":         "登录凭证格式错误",
+	"auth.token_expired":           "登录已过期",
+	"auth.token_signature_invalid": "登录凭证无效",
+	"auth.token_algorithm_invalid": "登录凭证无效",
+	"auth.token_not_valid_yet":     "登录凭证尚未生效，请稍后重试",
+	"auth.token_revoked":           "登录已失效，请重新登录",
+	"auth.token_outdated":          "登录状态已变更，请刷新登录",
+	"auth.token_claims_invalid":    "登录凭证无效",
+	"auth.user_unavailable":        "用户不可用",
+	"auth.service_unavailable":     "认证服务暂不可用，请稍后重试",
+	"auth.uid_not_found":           "未获取到用户 UID",
+	"auth.tenant_required":         "缺少租户信息",
+	"auth.cross_tenant_denied":     "禁止跨租户访问",
+	"csrf.origin_denied":           "请求来源不受信任",
+	"csrf.token_missing":           "缺少CSRF令牌",
+	"csrf.token_invalid":           "CSRF令牌无效",
+	"signature.headers_required":   "缺少签名请求头",
+	"signature.timestamp_invalid":  "时间戳格式错误",
+	"signature.timestamp_expired":  "时间戳已过期",
+	"signature.api_key_invalid":    "API Key无效",
+	"signature.body_read_failed":   "读取请求体失败",
+	"signature.body_too_large":     "请求体过大",
+	"signature.invalid":            "签名无效",
+	"signature.replayed":           "重复的请求",
+	"sms.mobiles_required":         "手机号列表不能为空",
+	"sms.params_invalid":           "参数序列化失败",
+	"sms.client_failed":            "创建阿里云客户端失败",
+	"sms.send_failed":              "发送短信失败",
+	"sms.rejected":                 "短信发送失败",
+}
+
+// enMessages 内置英文消息
+var enMessages = map[string]string{
+	"common.bad_request":           "bad request",
+	"common.unauthorized":          "unauthorized",
+	"common.forbidden":             "forbidden",
+	"common.not_found":             "not found",
+	"common.conflict":              "conflict",
+	"common.too_many_requests":     "too many requests",
+	"common.internal":              "internal error",
+	"common.service_unavailable":   "service unavailable",
+	"auth.token_missing":           "token required",
+	"auth.token_malformed":         "token malformed",
+	"auth.token_expired":           "token expired",
+	"auth.token_signature_invalid": "token signature invalid",
+	"auth.token_algorithm_invalid": "token algorithm invalid",
+	"auth.token_not_valid_yet":     "token not valid yet",
+	"auth.token_revoked":           "token revoked",
+	"auth.token_outdated":          "token outdated, please refresh",
+	"auth.token_claims_invalid":    "token claims invalid",
+	"auth.user_unavailable":        "user unavailable",
+	"auth.service_unavailable":     "service unavailable",
+	"auth.uid_not_found":           "user uid not found",
+	"auth.tenant_required":         "tenant required",
+	"auth.cross_tenant_denied":     "cross-tenant access denied",
+	"csrf.origin_denied":           "csrf origin denied",
+	"csrf.token_missing":           "csrf token missing",
+	"csrf.token_invalid":           "csrf token invalid",
+	"signature.headers_required":   "signature headers required",
+	"signature.timestamp_invalid":  "invalid timestamp",
+	"signature.timestamp_expired":  "timestamp expired",
+	"signature.api_key_invalid":    "invalid api key",
+	"signature.body_read_failed":   "read body failed",
+	"signature.body_too_large":     "request body too large",
+	"signature.invalid":            "invalid signature",
+	"signature.replayed":           "replayed request",
+	"sms.mobiles_required":         "mobile numbers required",
+	"sms.params_invalid":           "invalid sms parameters",
+	"sms.client_failed":            "create sms client failed",
+	"sms.send_failed":              "send sms failed",
+	"sms.rejected":                 "sms rejected by provider",
+}
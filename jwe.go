@@ -0,0 +1,164 @@
+package htxp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	// ErrJWEMalformed JWE格式错误
+	ErrJWEMalformed = errors.New("jwe token malformed")
+	// ErrJWEInvalid JWE解密失败（密钥不匹配或内容被篡改）
+	ErrJWEInvalid = errors.New("jwe token invalid")
+	// ErrJWEUnsupported 不支持的JWE算法（仅支持 dir + A256GCM）
+	ErrJWEUnsupported = errors.New("jwe algorithm unsupported")
+)
+
+// jweHeader JWE受保护头
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Cty string `json:"cty,omitempty"`
+}
+
+// IsJWE 判断令牌是否为紧凑序列化的JWE（五段式）
+func IsJWE(token string) bool {
+	return strings.Count(token, ".") == 4
+}
+
+// EncryptJWE 使用 dir + A256GCM 加密负载，输出紧凑序列化的JWE
+// key: 32字节内容加密密钥；cty: 负载类型，嵌套JWT时为 "JWT"
+func EncryptJWE(key, plaintext []byte, cty string) (string, error) {
+	header, err := json.Marshal(jweHeader{Alg: "dir", Enc: "A256GCM", Cty: cty})
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, 12)
+	if _, err = rand.Read(iv); err != nil {
+		return "", err
+	}
+	return encryptJWE(key, base64.RawURLEncoding.EncodeToString(header), iv, plaintext)
+}
+
+// encryptJWE 使用指定受保护头与IV执行 A256GCM 内容加密，便于按 RFC 7516 示例校验
+func encryptJWE(key []byte, protected string, iv, plaintext []byte) (string, error) {
+	gcm, err := newJWECipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return strings.Join([]string{
+		protected,
+		"",
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE 解密紧凑序列化的JWE（dir + A256GCM），返回负载
+func DecryptJWE(key []byte, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, ErrJWEMalformed
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWEMalformed
+	}
+	var header jweHeader
+	if err = json.Unmarshal(data, &header); err != nil {
+		return nil, ErrJWEMalformed
+	}
+	if header.Alg != "dir" || header.Enc != "A256GCM" || parts[1] != "" {
+		return nil, ErrJWEUnsupported
+	}
+	return decryptJWE(key, parts)
+}
+
+// decryptJWE 校验并解密 A256GCM 内容（不检查受保护头中的算法）
+func decryptJWE(key []byte, parts []string) ([]byte, error) {
+	gcm, err := newJWECipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(iv) != gcm.NonceSize() {
+		return nil, ErrJWEMalformed
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrJWEMalformed
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil || len(tag) != gcm.Overhead() {
+		return nil, ErrJWEMalformed
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, ErrJWEInvalid
+	}
+	return plaintext, nil
+}
+
+// GenEncryptedToken 先签名再加密（嵌套JWT）：HS256签名后使用 dir + A256GCM 加密，客户端无法读取声明
+func GenEncryptedToken[T TokenClaims](secretKey string, encryptionKey []byte, iat, seconds int64, claims T) (string, error) {
+	token, err := GenTokenFor(secretKey, iat, seconds, claims)
+	if err != nil {
+		return "", err
+	}
+	return EncryptJWE(encryptionKey, []byte(token), "JWT")
+}
+
+// GenEncryptedTokenForKeySet 使用密钥集签名后加密（嵌套JWT）
+func GenEncryptedTokenForKeySet[T TokenClaims](ks *KeySet, encryptionKey []byte, iat, seconds int64, claims T) (string, error) {
+	token, err := GenTokenForKeySet(ks, iat, seconds, claims)
+	if err != nil {
+		return "", err
+	}
+	return EncryptJWE(encryptionKey, []byte(token), "JWT")
+}
+
+// ParseEncryptedTokenAs 解密嵌套JWT并使用共享密钥验签，如 htxp.ParseEncryptedTokenAs[Claims](token, secret, key)
+func ParseEncryptedTokenAs[T any, PT interface {
+	*T
+	TokenClaims
+}](tokenString, secret string, encryptionKey []byte) (PT, error) {
+	inner, err := DecryptJWE(encryptionKey, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTokenAs[T, PT](string(inner), secret)
+}
+
+// ParseEncryptedTokenAsWithKeyfunc 解密嵌套JWT并使用自定义 Keyfunc 验签
+func ParseEncryptedTokenAsWithKeyfunc[T any, PT interface {
+	*T
+	TokenClaims
+}](tokenString string, encryptionKey []byte, keyFunc jwt.Keyfunc) (PT, error) {
+	inner, err := DecryptJWE(encryptionKey, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTokenAsWithKeyfunc[T, PT](string(inner), keyFunc)
+}
+
+func newJWECipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("jwe A256GCM key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
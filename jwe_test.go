@@ -0,0 +1,139 @@
+package htxp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 7516 附录 A.1 的 A256GCM 内容加密示例（其密钥管理为 RSA-OAEP，此处只校验内容加密）
+var (
+	rfc7516CEK = []byte{177, 161, 244, 128, 84, 143, 225, 115, 63, 180, 3, 255, 107, 154, 212, 246,
+		138, 7, 110, 91, 112, 46, 34, 105, 47, 130, 203, 46, 122, 234, 64, 252}
+	rfc7516IV         = []byte{227, 197, 117, 252, 2, 219, 233, 68, 180, 225, 77, 219}
+	rfc7516Protected  = "eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkEyNTZHQ00ifQ"
+	rfc7516Plaintext  = "The true sign of intelligence is not knowledge but imagination."
+	rfc7516Ciphertext = "5eym8TW_c8SuK0ltJ3rpYIzOeDQz7TALvtu6UG9oMo4vpzs9tX_EFShS8iB7j6jiSdiwkIr3ajwQzaBtQD_A"
+	rfc7516Tag        = "XFBoMYUZodetZdvTiFvSkQ"
+)
+
+func TestJWEContentEncryptionRFC7516(t *testing.T) {
+	token, err := encryptJWE(rfc7516CEK, rfc7516Protected, rfc7516IV, []byte(rfc7516Plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	if parts[2] != "48V1_ALb6US04U3b" || parts[3] != rfc7516Ciphertext || parts[4] != rfc7516Tag {
+		t.Fatalf("encrypt = %s", token)
+	}
+	plaintext, err := decryptJWE(rfc7516CEK, parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != rfc7516Plaintext {
+		t.Fatalf("decrypt = %q", plaintext)
+	}
+	// 示例的密钥管理算法为 RSA-OAEP，DecryptJWE 只接受 dir
+	if _, err = DecryptJWE(rfc7516CEK, token); !errors.Is(err, ErrJWEUnsupported) {
+		t.Fatalf("err = %v, want %v", err, ErrJWEUnsupported)
+	}
+}
+
+func TestJWERoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(rfc7516Plaintext)
+	token, err := EncryptJWE(key, plaintext, "JWT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsJWE(token) {
+		t.Fatalf("IsJWE(%s) = false", token)
+	}
+	got, err := DecryptJWE(key, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("decrypt = %q", got)
+	}
+}
+
+func TestJWERejects(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	token, err := EncryptJWE(key, []byte(rfc7516Plaintext), "JWT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	wrongKey := append([]byte{}, key...)
+	wrongKey[0] ^= 1
+	replace := func(i int, value string) string {
+		p := append([]string{}, parts...)
+		p[i] = value
+		return strings.Join(p, ".")
+	}
+	flip := func(s string) string {
+		b := []byte(s)
+		if b[0] == 'A' {
+			b[0] = 'B'
+		} else {
+			b[0] = 'A'
+		}
+		return string(b)
+	}
+
+	cases := map[string]struct {
+		key   []byte
+		token string
+		want  error
+	}{
+		"wrong key":          {wrongKey, token, ErrJWEInvalid},
+		"tampered header":    {key, replace(0, "eyJhbGciOiJkaXIiLCJlbmMiOiJBMjU2R0NNIn0"), ErrJWEInvalid},
+		"tampered iv":        {key, replace(2, flip(parts[2])), ErrJWEInvalid},
+		"tampered cipher":    {key, replace(3, flip(parts[3])), ErrJWEInvalid},
+		"tampered tag":       {key, replace(4, flip(parts[4])), ErrJWEInvalid},
+		"truncated cipher":   {key, replace(3, parts[3][:len(parts[3])-4]), ErrJWEInvalid},
+		"truncated tag":      {key, replace(4, parts[4][:8]), ErrJWEMalformed},
+		"truncated iv":       {key, replace(2, parts[2][:8]), ErrJWEMalformed},
+		"missing part":       {key, strings.Join(parts[:4], "."), ErrJWEMalformed},
+		"encrypted key":      {key, replace(1, "AAAA"), ErrJWEUnsupported},
+		"unsupported header": {key, replace(0, rfc7516Protected), ErrJWEUnsupported},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecryptJWE(c.key, c.token); !errors.Is(err, c.want) {
+				t.Fatalf("err = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestEncryptedTokenRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	token, err := GenEncryptedToken("secret", key, time.Now().Unix(), 60, &Claims{UserID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseEncryptedTokenAs[Claims](token, "secret", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 42 {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if _, err = ParseEncryptedTokenAs[Claims](token, "other", key); err == nil {
+		t.Fatal("token signed with another secret accepted")
+	}
+}
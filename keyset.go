@@ -0,0 +1,444 @@
+package htxp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+var (
+	// ErrKeyNotFound 未找到与 kid 对应的密钥
+	ErrKeyNotFound = errors.New("signing key not found")
+	// ErrNoSigningKey 密钥集中没有可用于签发的私钥
+	ErrNoSigningKey = errors.New("no active signing key")
+)
+
+// SigningKey 非对称签名密钥
+type SigningKey struct {
+	ID       string            // kid
+	Method   jwt.SigningMethod // jwt.SigningMethodRS256 / ES256 / EdDSA
+	Private  crypto.Signer     // 私钥（仅签发方需要，验签方可为空）
+	Public   crypto.PublicKey  // 公钥
+	Retiring bool              // 轮换中：不再用于签发，但仍可验签
+}
+
+// NewSigningKey 根据私钥创建签名密钥
+func NewSigningKey(kid string, method jwt.SigningMethod, private crypto.Signer) (*SigningKey, error) {
+	key := &SigningKey{
+		ID:      kid,
+		Method:  method,
+		Private: private,
+		Public:  private.Public(),
+	}
+	if err := key.validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateSigningKey 生成新的签名密钥（RS256 使用2048位RSA，ES256 使用P-256，EdDSA 使用Ed25519）
+func GenerateSigningKey(kid string, method jwt.SigningMethod) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch method.Alg() {
+	case jwt.SigningMethodRS256.Alg():
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256.Alg():
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %s", method.Alg())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(kid, method, private)
+}
+
+// validate 检查签名算法与公钥类型是否匹配
+func (k *SigningKey) validate() error {
+	if k.ID == "" {
+		return errors.New("signing key id required")
+	}
+	var ok bool
+	switch k.Method.Alg() {
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		_, ok = k.Public.(*rsa.PublicKey)
+	case jwt.SigningMethodES256.Alg():
+		var pub *ecdsa.PublicKey
+		pub, ok = k.Public.(*ecdsa.PublicKey)
+		ok = ok && pub.Curve == elliptic.P256()
+	case jwt.SigningMethodEdDSA.Alg():
+		_, ok = k.Public.(ed25519.PublicKey)
+	default:
+		return fmt.Errorf("unsupported signing method: %s", k.Method.Alg())
+	}
+	if !ok {
+		return fmt.Errorf("key type does not match signing method %s", k.Method.Alg())
+	}
+	return nil
+}
+
+// KeySet 签名密钥集，支持多个在用密钥与轮换中密钥并存
+// 写操作采用写时复制：总是替换整个切片与被修改的密钥，已取出的 *SigningKey 不会被修改
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []*SigningKey
+}
+
+// NewKeySet 创建密钥集
+func NewKeySet(keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{}
+	for _, key := range keys {
+		if err := ks.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// Add 添加密钥（kid 相同则替换），最后添加的未轮换私钥用于签发
+func (ks *KeySet) Add(key *SigningKey) error {
+	if err := key.validate(); err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = append(ks.without(key.ID), key)
+	return nil
+}
+
+// Rotate 添加新密钥并将其余密钥标记为轮换中（旧Token在过期前仍可验签）
+func (ks *KeySet) Rotate(key *SigningKey) error {
+	if err := key.validate(); err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	keys := ks.without(key.ID)
+	for i, k := range keys {
+		keys[i] = k.retired()
+	}
+	ks.keys = append(keys, key)
+	return nil
+}
+
+// Retire 将密钥标记为轮换中
+func (ks *KeySet) Retire(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	keys := make([]*SigningKey, len(ks.keys))
+	for i, k := range ks.keys {
+		if k.ID == kid {
+			k = k.retired()
+		}
+		keys[i] = k
+	}
+	ks.keys = keys
+}
+
+// Remove 移除密钥（由该密钥签发的Token将无法再验签）
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = ks.without(kid)
+}
+
+// without 返回不含指定 kid 的新切片（调用方需持有写锁）
+func (ks *KeySet) without(kid string) []*SigningKey {
+	keys := make([]*SigningKey, 0, len(ks.keys)+1)
+	for _, k := range ks.keys {
+		if k.ID != kid {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// retired 返回标记为轮换中的副本
+func (k *SigningKey) retired() *SigningKey {
+	if k.Retiring {
+		return k
+	}
+	c := *k
+	c.Retiring = true
+	return &c
+}
+
+// Lookup 根据 kid 查找密钥
+func (ks *KeySet) Lookup(kid string) (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for _, k := range ks.keys {
+		if k.ID == kid {
+			return k, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// SigningKey 获取当前用于签发的密钥
+func (ks *KeySet) SigningKey() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if k := ks.keys[i]; k.Private != nil && !k.Retiring {
+			return k, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Keyfunc 供 jwt.Parse 使用：按 kid 选择公钥，并要求算法与密钥一致
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token kid header required")
+	}
+	key, err := ks.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+	}
+	return key.Public, nil
+}
+
+// GenTokenWithKeySet 使用密钥集中的当前密钥签发jwt（写入 kid 头）
+func GenTokenWithKeySet(ks *KeySet, iat, seconds int64, claims jwt.MapClaims) (string, error) {
+	key, err := ks.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, stampClaims(claims, iat, seconds, DefaultTokenOptions))
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// JSONWebKey JWK公钥（RFC 7517）
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet JWKS
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS 导出密钥集中全部公钥（含轮换中的密钥）
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := JSONWebKey{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = pub.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// JWKSHandler 发布JWKS的HTTP处理器（如 /.well-known/jwks.json）
+func JWKSHandler(ks *KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		httpx.OkJson(w, ks.JWKS())
+	}
+}
+
+// ParseJWKS 从JWKS JSON解析出仅含公钥的密钥集
+// 跳过非签名用途（use 不为 sig）、不支持的类型或算法以及格式错误的密钥，没有可用密钥时返回错误
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var set JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	ks := &KeySet{}
+	var skipped []error
+	for _, jwk := range set.Keys {
+		key, err := jwk.signingKey()
+		if err == nil {
+			err = ks.Add(key)
+		}
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("jwk %q: %w", jwk.Kid, err))
+		}
+	}
+	if len(ks.keys) == 0 {
+		return nil, fmt.Errorf("no usable signing key in jwks: %w", errors.Join(skipped...))
+	}
+	for _, err := range skipped {
+		logx.Infof("跳过JWKS中的密钥: %v", err)
+	}
+	return ks, nil
+}
+
+// signingKey 将JWK转换为仅含公钥的签名密钥，签名算法以 alg 为准（缺省时按密钥类型推断）
+func (jwk JSONWebKey) signingKey() (*SigningKey, error) {
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return nil, fmt.Errorf("unsupported key use: %s", jwk.Use)
+	}
+	key := &SigningKey{ID: jwk.Kid}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		key.Method = jwt.SigningMethodRS256
+		if jwk.Alg != "" {
+			key.Method = jwt.GetSigningMethod(jwk.Alg)
+		}
+		key.Public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		if jwk.Crv != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key.Method = jwt.SigningMethodES256
+		key.Public = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		key.Method = jwt.SigningMethodEdDSA
+		key.Public = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+	if key.Method == nil || jwk.Alg != "" && jwk.Alg != key.Method.Alg() {
+		return nil, fmt.Errorf("unsupported algorithm %s for key type %s", jwk.Alg, jwk.Kty)
+	}
+	return key, key.validate()
+}
+
+// jwksClient 获取JWKS的默认HTTP客户端，避免远程无响应时一直阻塞
+var jwksClient = &http.Client{Timeout: 10 * time.Second}
+
+// maxJWKSSize JWKS响应体的最大字节数
+const maxJWKSSize = 1 << 20
+
+// FetchJWKS 从远程地址获取JWKS（默认超时10秒）
+func FetchJWKS(ctx context.Context, url string) (*KeySet, error) {
+	return FetchJWKSWithClient(ctx, jwksClient, url)
+}
+
+// FetchJWKSWithClient 使用指定的HTTP客户端获取JWKS（如自定义超时、代理或TLS配置）
+func FetchJWKSWithClient(ctx context.Context, client *http.Client, url string) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// defaultJWKSRefreshInterval 未指定刷新间隔时的默认值
+const defaultJWKSRefreshInterval = 5 * time.Minute
+
+// WatchJWKS 获取远程JWKS并按间隔刷新（验签方使用，ctx 结束时停止刷新，interval<=0 时每5分钟刷新）
+func WatchJWKS(ctx context.Context, url string, interval time.Duration) (*KeySet, error) {
+	return WatchJWKSWithClient(ctx, jwksClient, url, interval)
+}
+
+// WatchJWKSWithClient 使用指定的HTTP客户端获取并刷新远程JWKS
+func WatchJWKSWithClient(ctx context.Context, client *http.Client, url string, interval time.Duration) (*KeySet, error) {
+	if interval <= 0 {
+		interval = defaultJWKSRefreshInterval
+	}
+	ks, err := FetchJWKSWithClient(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				latest, err := FetchJWKSWithClient(ctx, client, url)
+				if err != nil {
+					logx.Errorf("刷新JWKS失败: %v", err)
+					continue
+				}
+				latest.mu.RLock()
+				keys := latest.keys
+				latest.mu.RUnlock()
+				ks.mu.Lock()
+				ks.keys = keys
+				ks.mu.Unlock()
+			}
+		}
+	}()
+	return ks, nil
+}
@@ -0,0 +1,122 @@
+package htxp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestParseJWKSSkipsUnusableKeys(t *testing.T) {
+	rsaKey, err := GenerateSigningKey("rsa", jwt.SigningMethodRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := GenerateSigningKey("ec", jwt.SigningMethodES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeySet(rsaKey, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := ks.JWKS().Keys
+	rsaJWK, ecJWK := exported[0], exported[1]
+
+	ps256 := rsaJWK
+	ps256.Kid, ps256.Alg = "ps256", "PS256"
+	noAlg := rsaJWK
+	noAlg.Kid, noAlg.Alg, noAlg.Use = "no-alg", "", ""
+	enc := rsaJWK
+	enc.Kid, enc.Use, enc.Alg = "enc", "enc", "RSA-OAEP"
+	hmacAlg := rsaJWK
+	hmacAlg.Kid, hmacAlg.Alg = "hs256", "HS256"
+	ecMismatch := ecJWK
+	ecMismatch.Kid, ecMismatch.Alg = "es384", "ES384"
+	oct := JSONWebKey{Kty: "oct", Kid: "oct", Use: "sig", Alg: "HS256"}
+
+	data, err := json.Marshal(JSONWebKeySet{Keys: []JSONWebKey{rsaJWK, ecJWK, ps256, noAlg, enc, hmacAlg, ecMismatch, oct}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"rsa": "RS256", "ec": "ES256", "ps256": "PS256", "no-alg": "RS256"}
+	for kid, alg := range want {
+		key, err := parsed.Lookup(kid)
+		if err != nil {
+			t.Fatalf("%s: %v", kid, err)
+		}
+		if key.Method.Alg() != alg {
+			t.Fatalf("%s: alg = %s, want %s", kid, key.Method.Alg(), alg)
+		}
+	}
+	for _, kid := range []string{"enc", "hs256", "es384", "oct"} {
+		if _, err = parsed.Lookup(kid); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("%s: err = %v, want %v", kid, err, ErrKeyNotFound)
+		}
+	}
+
+	data, err = json.Marshal(JSONWebKeySet{Keys: []JSONWebKey{enc, oct}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParseJWKS(data); err == nil {
+		t.Fatal("jwks without usable keys accepted")
+	}
+}
+
+func TestGenTokenWithKeySet(t *testing.T) {
+	key, err := GenerateSigningKey("k1", jwt.SigningMethodEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeySet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iat := time.Now().Unix()
+	token, err := GenTokenWithKeySet(ks, iat, 60, jwt.MapClaims{"uid": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, ks.Keyfunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "k1" || claims["jti"] == nil || claims["exp"] != float64(iat+60) {
+		t.Fatalf("header = %v, claims = %v", parsed.Header, claims)
+	}
+}
+
+func TestWatchJWKSDefaultInterval(t *testing.T) {
+	key, err := GenerateSigningKey("k1", jwt.SigningMethodES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeySet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(JWKSHandler(ks))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// interval 为 0 时使用默认间隔，而不是让 time.NewTicker panic
+	watched, err := WatchJWKSWithClient(ctx, http.DefaultClient, server.URL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = watched.Lookup("k1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -366,11 +366,17 @@ func GetUserIdAsUint64(data map[string]interface{}) (uint64, error) {
 	return userId, nil
 }
 
+// ErrUIDNotFound 未获取到用户 UID
+var ErrUIDNotFound = RegisterCode(40111, 401, "auth.uid_not_found", "user uid not found")
+
+// GetUIDFromContext 从请求context的 payload 中获取用户 UID
+//
+// Deprecated: 使用 middleware.UserFromContext(r.Context())
 func GetUIDFromContext(r *http.Request) (uint64, error) {
 	// 1. 获取用户 UID
 	uid, ok := r.Context().Value("payload").(string)
 	if !ok {
-		return 0, fmt.Errorf("未获取到用户 UID")
+		return 0, ErrUIDNotFound
 	}
 	// 2. 转换为 uint64
 	var uidInt uint64
@@ -381,11 +387,14 @@ func GetUIDFromContext(r *http.Request) (uint64, error) {
 	return uidInt, nil
 }
 
+// GetUIDFromLogic 从logic层context的 payload 中获取用户 UID
+//
+// Deprecated: 使用 middleware.UserFromContext(ctx)
 func GetUIDFromLogic(ctx context.Context) (uint64, error) {
 	// 1. 获取用户 UID
 	uid, ok := ctx.Value("payload").(string)
 	if !ok {
-		return 0, fmt.Errorf("未获取到用户 UID")
+		return 0, ErrUIDNotFound
 	}
 	// 2. 转换为 uint64
 	var uidInt uint64
@@ -2,9 +2,10 @@ package middleware
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/linktomarkdown/htxp"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -15,51 +16,223 @@ import (
 type AuthGuardMiddleware struct {
 	jwtSecret string
 	redis     *redis.Redis
+	opts      AuthGuardOptions
+}
+
+// AuthGuardOptions 认证守卫可选配置
+type AuthGuardOptions struct {
+	// KeySet 公钥密钥集（可由 htxp.FetchJWKS / htxp.WatchJWKS 获取），设置后使用公钥验签
+	KeySet *htxp.KeySet
+	// PasetoLocalKey PASETO v4.local 对称密钥，设置后接受 v4.local 令牌
+	PasetoLocalKey []byte
+	// PasetoPublicKey PASETO v4.public 验签公钥，设置后接受 v4.public 令牌
+	PasetoPublicKey ed25519.PublicKey
+	// EncryptionKey JWE（dir + A256GCM）内容加密密钥，设置后接受加密的嵌套JWT，解密后再验签
+	EncryptionKey []byte
+	// Validator 声明校验配置（签发方、受众、时钟偏差、签名算法）
+	Validator ValidatorOptions
+	// PrincipalLoader 加载用户主体信息（VIP、已购课程等），为空时仅使用Token中的声明
+	PrincipalLoader PrincipalLoader
+	// TokenSources 令牌来源（按顺序尝试），为空时使用 DefaultTokenSources
+	TokenSources []TokenSource
+	// VersionChecker Token版本检查器（本地缓存与故障策略），为空时每次查询Redis且故障时拒绝
+	VersionChecker *TokenVersionChecker
+	// BlacklistChecker Token黑名单检查器（本地缓存与故障策略），为空时每次查询Redis，
+	// 故障策略沿用 VersionChecker（VersionFailCached 仅依据本地已知的吊销记录，其余放行）
+	BlacklistChecker *TokenBlacklistChecker
+	// ImpersonationMaxTTL 接受的模拟登录Token最长有效期，默认 DefaultImpersonationMaxTTL
+	ImpersonationMaxTTL time.Duration
+	// ImpersonationRoles 模拟登录时保留的角色，为空时使用Token中的角色（签发时已限定）
+	ImpersonationRoles []string
+	// AuditLogger 模拟登录请求的审计记录器，默认写入 logx
+	AuditLogger AuditLogger
+	// TrustedProxies 可信代理，审计记录的客户端IP仅采信这些代理转发的请求头，为空时使用 RemoteAddr
+	TrustedProxies TrustedProxies
+	// Optional 可选认证：携带有效Token时注入用户，否则按游客放行
+	Optional bool
+	// OptionalRoutes 可选认证的路由（其余路由仍强制认证）
+	OptionalRoutes []RouteRule
+	// PublicRoutes 无需认证的路由，便于一个守卫保护整个路由组
+	PublicRoutes []RouteRule
+	// AcceptLegacyTokens 兼容 htxp.GenTokenWithPayload / GenTokenWithUser 签发的旧版Token（共享密钥）
+	AcceptLegacyTokens bool
 }
 
 // NewAuthGuardMiddleware 创建认证守卫中间件
 // jwtSecret: JWT签名密钥，需要与iam-rpc保持一致
 // redis: Redis客户端，用于Token版本检查
 func NewAuthGuardMiddleware(jwtSecret string, redis *redis.Redis) *AuthGuardMiddleware {
+	return NewAuthGuardMiddlewareWithOptions(jwtSecret, redis, AuthGuardOptions{})
+}
+
+// NewAuthGuardMiddlewareWithKeySet 创建基于公钥验签的认证守卫中间件
+// keySet: 公钥密钥集（可由 htxp.FetchJWKS / htxp.WatchJWKS 获取），无需持有签发私钥
+// redis: Redis客户端，用于Token版本检查
+func NewAuthGuardMiddlewareWithKeySet(keySet *htxp.KeySet, redis *redis.Redis) *AuthGuardMiddleware {
+	return NewAuthGuardMiddlewareWithOptions("", redis, AuthGuardOptions{KeySet: keySet})
+}
+
+// NewAuthGuardMiddlewareWithOptions 创建带可选配置的认证守卫中间件
+func NewAuthGuardMiddlewareWithOptions(jwtSecret string, redis *redis.Redis, opts AuthGuardOptions) *AuthGuardMiddleware {
+	if opts.VersionChecker == nil {
+		opts.VersionChecker = NewTokenVersionChecker(redis, TokenVersionConf{})
+	}
+	if opts.BlacklistChecker == nil {
+		opts.BlacklistChecker = NewTokenBlacklistChecker(redis, TokenBlacklistConf{
+			FailurePolicy: opts.VersionChecker.conf.FailurePolicy,
+		})
+	}
+	if opts.ImpersonationMaxTTL <= 0 {
+		opts.ImpersonationMaxTTL = DefaultImpersonationMaxTTL
+	}
 	return &AuthGuardMiddleware{
 		jwtSecret: jwtSecret,
 		redis:     redis,
+		opts:      opts,
 	}
 }
 
 // Handle 处理HTTP请求，验证JWT Token
 func (m *AuthGuardMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// 1. 提取Token
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			htxp.ErrorWithCode(w, errors.New("authorization header required"), 401)
+		// 公开路由无需认证
+		if MatchRoutes(m.opts.PublicRoutes, r) {
+			next(w, r)
 			return
 		}
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			htxp.ErrorWithCode(w, errors.New("invalid authorization header format"), 401)
+		optional := m.opts.Optional || MatchRoutes(m.opts.OptionalRoutes, r)
+
+		// 1. 提取Token
+		token, err := ExtractToken(r, m.opts.TokenSources)
+		if err != nil {
+			if optional {
+				next(w, r)
+				return
+			}
+			writeAuthError(r.Context(), w, err)
 			return
 		}
-		token := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// 2. 本地验证JWT（无需RPC调用）
-		claims, err := ParseToken(token, m.jwtSecret)
+		// 2. 验证Token并加载用户主体信息
+		principal, err := m.authenticate(r.Context(), token)
 		if err != nil {
-			logx.Errorf("JWT解析失败: %v", err)
-			htxp.ErrorWithCode(w, errors.New("invalid token"), 401)
+			if optional {
+				// 可选认证：Token无效时按游客处理
+				next(w, r)
+				return
+			}
+			writeAuthError(r.Context(), w, err)
 			return
 		}
+		ctx := context.WithValue(r.Context(), ContextKeyToken, token)
+		ctx = WithPrincipal(ctx, principal)
+		m.audit(ctx, principal, r.Method, r.URL.Path, m.clientIP(r))
+		next(w, r.WithContext(ctx))
+	}
+}
 
-		// 3. 检查Token版本号
-		if !CheckTokenVersion(m.redis, claims.UserID, claims.Version) {
-			logx.Errorf("Token版本过期: userID=%d, tokenVersion=%d", claims.UserID, claims.Version)
-			htxp.ErrorWithCode(w, errors.New("token expired, please refresh"), 401)
-			return
+// clientIP 已由限流等中间件解析过时沿用其结果，否则按守卫的可信代理解析
+func (m *AuthGuardMiddleware) clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ContextKeyClientIP).(string); ok && ip != "" {
+		return ip
+	}
+	return m.opts.TrustedProxies.ClientIP(r)
+}
+
+// authenticate 验证Token并返回用户主体，失败时返回本包的哨兵错误
+func (m *AuthGuardMiddleware) authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := m.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 加载用户主体信息
+	principal, err := loadPrincipal(ctx, m.opts.PrincipalLoader, claims)
+	if err != nil {
+		logx.Errorf("加载用户信息失败: userID=%d, err=%v", claims.UserID, err)
+		return nil, ErrUserUnavailable
+	}
+	return principal, nil
+}
+
+// verify 验证Token签名、声明、黑名单与版本号，失败时返回本包的哨兵错误
+func (m *AuthGuardMiddleware) verify(ctx context.Context, token string) (*JWTClaims, error) {
+	// 1. 本地验证JWT（无需RPC调用）
+	claims, err := m.parseToken(token)
+	if err != nil {
+		logx.Errorf("JWT解析失败: %v", err)
+		return nil, ClassifyTokenError(err)
+	}
+
+	// 2. 模拟登录Token：限制有效期与角色
+	if claims.Act != nil {
+		if claims.ExpiresAt == nil || claims.IssuedAt == nil ||
+			claims.ExpiresAt.Sub(claims.IssuedAt.Time) > m.opts.ImpersonationMaxTTL {
+			logx.Errorf("模拟登录Token有效期超限: actorID=%d, userID=%d", claims.Act.ActorID, claims.UserID)
+			return nil, ErrTokenClaimsInvalid
+		}
+		if len(m.opts.ImpersonationRoles) > 0 {
+			claims.Roles = intersectRoles(claims.Roles, m.opts.ImpersonationRoles)
 		}
+	}
 
-		// 4. 将用户ID放到context
-		ctx := context.WithValue(r.Context(), ContextKeyUserID, claims.UserID)
-		next(w, r.WithContext(ctx))
+	// 3. 检查Token黑名单
+	if err = m.opts.BlacklistChecker.Check(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			logx.Errorf("Token已吊销: userID=%d, jti=%s", claims.UserID, claims.ID)
+			return nil, ErrTokenRevoked
+		}
+		logx.Errorf("Token黑名单检查失败: userID=%d, err=%v", claims.UserID, err)
+		return nil, ErrAuthUnavailable
+	}
+
+	// 4. 检查Token版本号
+	if err = m.opts.VersionChecker.Check(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenOutdated) {
+			logx.Errorf("Token版本过期: userID=%d, tokenVersion=%d", claims.UserID, claims.Version)
+			return nil, ErrTokenOutdated
+		}
+		logx.Errorf("Token版本检查失败: userID=%d, err=%v", claims.UserID, err)
+		return nil, ErrAuthUnavailable
 	}
+	return claims, nil
 }
 
+// parseToken 按令牌格式选择 PASETO 或 JWT；JWT 配置了密钥集时使用公钥验签，否则使用共享密钥
+// JWE 令牌先解密，再按内层令牌的格式验签
+func (m *AuthGuardMiddleware) parseToken(token string) (*JWTClaims, error) {
+	if htxp.IsJWE(token) {
+		if len(m.opts.EncryptionKey) == 0 {
+			return nil, ErrTokenAlgorithmInvalid
+		}
+		inner, err := htxp.DecryptJWE(m.opts.EncryptionKey, token)
+		if err != nil {
+			return nil, err
+		}
+		token = string(inner)
+	}
+	if htxp.IsPaseto(token) {
+		return ParsePaseto(token, m.opts.PasetoLocalKey, m.opts.PasetoPublicKey, m.opts.Validator)
+	}
+	if m.opts.KeySet != nil {
+		return ParseTokenWithOptions(token, m.opts.KeySet.Keyfunc, m.opts.Validator)
+	}
+	if m.jwtSecret == "" {
+		// 仅配置了 PASETO / JWE 密钥的守卫不接受任何JWT，避免以空密钥验签
+		return nil, ErrTokenAlgorithmInvalid
+	}
+	claims, err := ParseTokenWithOptions(token, SecretKeyfunc(m.jwtSecret), m.opts.Validator)
+	if err != nil || claims.UserID != 0 {
+		return claims, err
+	}
+	if !m.opts.AcceptLegacyTokens {
+		// 签名有效但没有 user_id 声明（如旧版Token），未开启兼容时拒绝
+		return nil, ErrTokenClaimsInvalid
+	}
+	// 旧版Token没有 user_id 声明，签名与时间已校验，转换 uid/payload 声明
+	legacy, err := htxp.ParseLegacyToken(token, m.jwtSecret)
+	if err != nil {
+		return nil, ErrTokenClaimsInvalid
+	}
+	return legacy, nil
+}
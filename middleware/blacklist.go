@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// TokenBlacklistChannel Token吊销通知频道（消息格式："<jti> <过期时间戳>"）
+const TokenBlacklistChannel = "token:blacklist:added"
+
+// blacklistSweepInterval 清理本地过期黑名单缓存的间隔
+const blacklistSweepInterval = time.Minute
+
+// TokenBlacklistConf Token黑名单检查配置
+type TokenBlacklistConf struct {
+	// FailurePolicy Redis不可用时的策略：VersionFailClosed 拒绝；VersionFailOpen 放行；
+	// VersionFailCached 仅依据本地已知的吊销记录（含 pub/sub 通知），其余Token放行
+	FailurePolicy VersionFailurePolicy
+	// CacheTTL 未吊销结果的本地缓存有效期，0 表示每次请求都查询Redis
+	// 未订阅通知的实例在该时长内可能仍接受刚吊销的Token；已吊销结果始终缓存至Token过期
+	CacheTTL time.Duration
+}
+
+// TokenBlacklistChecker 带本地缓存与故障策略的Token黑名单检查器
+// 缓存通过 Redis pub/sub（TokenBlacklistChannel）更新，见 Subscribe
+type TokenBlacklistChecker struct {
+	redis     *redis.Redis
+	conf      TokenBlacklistConf
+	mu        sync.RWMutex
+	entries   map[string]blacklistEntry
+	lastSweep time.Time
+}
+
+type blacklistEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewTokenBlacklistChecker 创建Token黑名单检查器
+func NewTokenBlacklistChecker(rds *redis.Redis, conf TokenBlacklistConf) *TokenBlacklistChecker {
+	return &TokenBlacklistChecker{
+		redis:     rds,
+		conf:      conf,
+		entries:   make(map[string]blacklistEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Check 检查Token是否已吊销，已吊销返回 ErrTokenRevoked
+// 按故障策略无法确认时返回Redis错误
+func (c *TokenBlacklistChecker) Check(ctx context.Context, claims *JWTClaims) error {
+	if claims.ID == "" {
+		return nil
+	}
+	entry, cached := c.lookup(claims.ID)
+	if cached && time.Now().Before(entry.expiresAt) {
+		if entry.revoked {
+			return ErrTokenRevoked
+		}
+		return nil
+	}
+
+	revoked, err := IsTokenBlacklisted(ctx, c.redis, claims.ID)
+	if err != nil {
+		switch c.conf.FailurePolicy {
+		case VersionFailOpen, VersionFailCached:
+			// 已知的吊销记录在上方命中，此处均为本地未知的Token
+			logx.Errorf("Token黑名单检查失败，按策略放行: jti=%s, err=%v", claims.ID, err)
+			return nil
+		}
+		return err
+	}
+	if revoked {
+		expiresAt := time.Now().Add(time.Hour)
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		c.store(claims.ID, blacklistEntry{revoked: true, expiresAt: expiresAt})
+		return ErrTokenRevoked
+	}
+	if c.conf.CacheTTL > 0 {
+		c.store(claims.ID, blacklistEntry{expiresAt: time.Now().Add(c.conf.CacheTTL)})
+	}
+	return nil
+}
+
+// Subscribe 订阅Token吊销通知以更新本地缓存，ctx 结束时退出
+func (c *TokenBlacklistChecker) Subscribe(ctx context.Context, client *htxp.CacheClient) {
+	pubsub := client.Subscribe(ctx, TokenBlacklistChannel)
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				c.apply(msg.Payload)
+			}
+		}
+	}()
+}
+
+func (c *TokenBlacklistChecker) lookup(jti string) (blacklistEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[jti]
+	return entry, ok
+}
+
+func (c *TokenBlacklistChecker) store(jti string, entry blacklistEntry) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[jti] = entry
+	// 定期清理已过期的缓存
+	if now.Sub(c.lastSweep) > blacklistSweepInterval {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+}
+
+// apply 处理吊销通知
+func (c *TokenBlacklistChecker) apply(payload string) {
+	jti, exp, ok := strings.Cut(payload, " ")
+	if !ok || jti == "" {
+		return
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return
+	}
+	c.store(jti, blacklistEntry{revoked: true, expiresAt: time.Unix(unix, 0)})
+}
+
+// BlacklistToken 将 jti 加入黑名单，黑名单随Token过期自动清除
+func BlacklistToken(ctx context.Context, rds *redis.Redis, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("token jti required")
+	}
+	seconds := int(time.Until(expiresAt).Seconds()) + 1
+	if seconds <= 1 {
+		// Token已过期，无需加入黑名单
+		return nil
+	}
+	if err := rds.SetexCtx(ctx, tokenBlacklistKey(jti), "1", seconds); err != nil {
+		return err
+	}
+	// 通知各实例更新本地黑名单缓存
+	if _, err := rds.PublishCtx(ctx, TokenBlacklistChannel, fmt.Sprintf("%s %d", jti, expiresAt.Unix())); err != nil {
+		logx.Errorf("发布Token吊销通知失败: %v", err)
+	}
+	return nil
+}
+
+// IsTokenBlacklisted 检查 jti 是否在黑名单中
+func IsTokenBlacklisted(ctx context.Context, rds *redis.Redis, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	return rds.ExistsCtx(ctx, tokenBlacklistKey(jti))
+}
+
+// RevokeToken 吊销单个Token（如退出登录、Token被盗），使用共享密钥验签
+func RevokeToken(ctx context.Context, rds *redis.Redis, tokenString, secret string) error {
+	return revokeClaims(ctx, rds, func() (*JWTClaims, error) {
+		return ParseToken(tokenString, secret)
+	})
+}
+
+// RevokeToken 使用守卫的验签配置吊销单个Token
+func (m *AuthGuardMiddleware) RevokeToken(ctx context.Context, tokenString string) error {
+	return revokeClaims(ctx, m.redis, func() (*JWTClaims, error) {
+		return m.parseToken(tokenString)
+	})
+}
+
+func revokeClaims(ctx context.Context, rds *redis.Redis, parse func() (*JWTClaims, error)) error {
+	claims, err := parse()
+	if err != nil {
+		if ClassifyTokenError(err) == ErrTokenExpired {
+			// 已过期的Token无需吊销
+			return nil
+		}
+		return err
+	}
+	if claims.ExpiresAt == nil {
+		return errors.New("token exp required")
+	}
+	return BlacklistToken(ctx, rds, claims.ID, claims.ExpiresAt.Time)
+}
+
+func tokenBlacklistKey(jti string) string {
+	return fmt.Sprintf("token:blacklist:%s", jti)
+}
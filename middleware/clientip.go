@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies 可信代理网段，仅当请求直接来自这些地址时才采信 X-Forwarded-For / X-Real-IP
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies 解析可信代理列表，支持 CIDR（10.0.0.0/8）与单个IP
+func ParseTrustedProxies(cidrs ...string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// ClientIP 解析客户端IP：直连地址不可信时直接使用 RemoteAddr；
+// 否则从 X-Forwarded-For 右侧向左跳过可信代理，取第一个不可信的地址
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	remote := remoteIP(r)
+	addr, err := netip.ParseAddr(remote)
+	if err != nil || !t.trusted(addr) {
+		return remote
+	}
+
+	xff := r.Header.Values("X-Forwarded-For")
+	if len(xff) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+		return remote
+	}
+	hops := strings.Split(strings.Join(xff, ","), ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// 无法解析的地址由上一跳伪造或篡改，停在最后一个可信的地址
+			break
+		}
+		client = hop.Unmap().String()
+		if !t.trusted(hop) {
+			break
+		}
+	}
+	return client
+}
+
+func (t TrustedProxies) trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 获取客户端IP：优先使用中间件按可信代理解析后写入context的地址，否则使用 RemoteAddr
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ContextKeyClientIP).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// withClientIP 将解析后的客户端IP写入请求context
+func withClientIP(r *http.Request, proxies TrustedProxies) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ContextKeyClientIP, proxies.ClientIP(r)))
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
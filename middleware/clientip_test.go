@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1", "fd00::/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		remote string
+		xff    []string
+		realIP string
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"untrusted hop spoofs xff", "203.0.113.7:5000", []string{"1.2.3.4"}, "", "203.0.113.7"},
+		{"untrusted hop spoofs real ip", "203.0.113.7:5000", nil, "1.2.3.4", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:80", []string{"198.51.100.9"}, "", "198.51.100.9"},
+		{"spoofed entry left of client", "10.0.0.1:80", []string{"1.2.3.4, 198.51.100.9"}, "", "198.51.100.9"},
+		{"proxy chain", "10.0.0.1:80", []string{"1.2.3.4, 198.51.100.9, 10.0.0.2", "192.168.1.1"}, "", "198.51.100.9"},
+		{"all hops trusted", "10.0.0.1:80", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		{"garbage hop", "10.0.0.1:80", []string{"198.51.100.9, not-an-ip, 10.0.0.2"}, "", "10.0.0.2"},
+		{"real ip from trusted proxy", "10.0.0.1:80", nil, "198.51.100.9", "198.51.100.9"},
+		{"invalid real ip", "10.0.0.1:80", nil, "not-an-ip", "10.0.0.1"},
+		{"ipv4-mapped proxy", "[::ffff:10.0.0.1]:80", []string{"198.51.100.9"}, "", "198.51.100.9"},
+		{"ipv6 proxy", "[fd00::1]:80", []string{"2001:db8::1"}, "", "2001:db8::1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = c.remote
+			for _, v := range c.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if c.realIP != "" {
+				r.Header.Set("X-Real-IP", c.realIP)
+			}
+			if got := proxies.ClientIP(r); got != c.want {
+				t.Fatalf("ClientIP = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if _, err := ParseTrustedProxies(cidr); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) accepted", cidr)
+		}
+	}
+}
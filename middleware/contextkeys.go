@@ -6,6 +6,18 @@ type contextKey string
 const (
 	// ContextKeyUserID 用户ID
 	ContextKeyUserID contextKey = "userId"
+	// ContextKeyToken 原始Token（用于RPC调用时透传）
+	ContextKeyToken contextKey = "token"
+	// ContextKeyTenantID 租户ID
+	ContextKeyTenantID contextKey = "tenantId"
+	// ContextKeyImpersonatorID 模拟登录的实际操作人ID
+	ContextKeyImpersonatorID contextKey = "impersonatorId"
+	// ContextKeyRoles 角色列表
+	ContextKeyRoles contextKey = "roles"
+	// ContextKeyAPIKeyID 请求签名使用的API Key
+	ContextKeyAPIKeyID contextKey = "apiKeyId"
+	// ContextKeyClientIP 按可信代理解析的客户端IP
+	ContextKeyClientIP contextKey = "clientIp"
 	// ContextKeyIsAdmin 是否是管理员
 	ContextKeyIsAdmin contextKey = "isAdmin"
 	// ContextKeyUserInfo 用户完整信息（可选）
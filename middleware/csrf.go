@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// CSRF校验失败子码（写入 Body.Code），子码除以100即为对应的HTTP状态码
+const (
+	CodeCSRFOriginDenied = 40301 // 请求来源不被信任
+	CodeCSRFTokenMissing = 40302 // 缺少CSRF令牌
+	CodeCSRFTokenInvalid = 40303 // CSRF令牌无效
+)
+
+var (
+	// ErrCSRFOriginDenied 请求来源不被信任
+	ErrCSRFOriginDenied = htxp.RegisterCode(CodeCSRFOriginDenied, 403, "csrf.origin_denied", "csrf origin denied")
+	// ErrCSRFTokenMissing 缺少CSRF令牌
+	ErrCSRFTokenMissing = htxp.RegisterCode(CodeCSRFTokenMissing, 403, "csrf.token_missing", "csrf token missing")
+	// ErrCSRFTokenInvalid CSRF令牌无效
+	ErrCSRFTokenInvalid = htxp.RegisterCode(CodeCSRFTokenInvalid, 403, "csrf.token_invalid", "csrf token invalid")
+)
+
+// minCSRFSecretLen CSRF签名密钥的最小长度
+const minCSRFSecretLen = 32
+
+// CSRFOptions CSRF防护中间件配置
+type CSRFOptions struct {
+	Secret         string        // 令牌签名密钥，至少32字节（可由 htxp.GenerateKey(32) 生成）
+	CookieName     string        // CSRF令牌Cookie名，默认 csrf_token
+	HeaderName     string        // 提交CSRF令牌的请求头，默认 X-CSRF-Token
+	TrustedOrigins []string      // 信任的来源（如 https://app.example.com），为空时要求与 Host 同源
+	AuthCookie     string        // 携带登录Token的Cookie名，设置后未携带该Cookie的请求（如使用请求头认证）跳过校验
+	CookieDomain   string        // CSRF令牌Cookie的域
+	CookiePath     string        // CSRF令牌Cookie的路径，默认 /
+	Secure         bool          // CSRF令牌Cookie仅通过HTTPS发送
+	MaxAge         time.Duration // CSRF令牌有效期，默认 12 小时
+	// SessionID 会话标识，令牌签名与其绑定，防止令牌在会话间复用；默认使用 AuthCookie 的值
+	// 登录后会话变化，旧令牌随之失效，应通过安全方法请求获取新令牌
+	SessionID func(r *http.Request) string
+}
+
+// CSRFMiddleware 基于签名双提交Cookie的CSRF防护中间件
+// 安全方法（GET/HEAD/OPTIONS/TRACE）放行并下发令牌Cookie；其余方法校验 Origin/Referer，
+// 并要求请求头中的令牌与Cookie一致且签名有效
+type CSRFMiddleware struct {
+	opts CSRFOptions
+}
+
+// NewCSRFMiddleware 创建CSRF防护中间件，签名密钥为空或过短时 panic
+func NewCSRFMiddleware(opts CSRFOptions) *CSRFMiddleware {
+	if len(opts.Secret) < minCSRFSecretLen {
+		panic(fmt.Sprintf("htxp: csrf secret must be at least %d bytes", minCSRFSecretLen))
+	}
+	if opts.CookieName == "" {
+		opts.CookieName = "csrf_token"
+	}
+	if opts.HeaderName == "" {
+		opts.HeaderName = "X-CSRF-Token"
+	}
+	if opts.CookiePath == "" {
+		opts.CookiePath = "/"
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 12 * time.Hour
+	}
+	if opts.SessionID == nil {
+		opts.SessionID = cookieSessionID(opts.AuthCookie)
+	}
+	return &CSRFMiddleware{opts: opts}
+}
+
+// Handle 处理HTTP请求，校验CSRF令牌
+func (m *CSRFMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) {
+			if _, err := m.Token(w, r); err != nil {
+				logx.Errorf("生成CSRF令牌失败: %v", err)
+			}
+			next(w, r)
+			return
+		}
+		if m.opts.AuthCookie != "" {
+			if _, err := r.Cookie(m.opts.AuthCookie); err != nil {
+				next(w, r)
+				return
+			}
+		}
+		if err := m.verify(r); err != nil {
+			htxp.ErrorWithCodeCtx(r.Context(), w, err, 403)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Token 获取当前有效的CSRF令牌，不存在或无效时生成新令牌并写入Cookie
+func (m *CSRFMiddleware) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	session := m.opts.SessionID(r)
+	if cookie, err := r.Cookie(m.opts.CookieName); err == nil && m.validToken(cookie.Value, session) {
+		return cookie.Value, nil
+	}
+	nonce, err := htxp.GenerateKey(32)
+	if err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(m.opts.MaxAge).Unix()
+	payload := strings.Join([]string{nonce, htxp.FormatUnixToString(expires)}, ".")
+	token := payload + "." + m.sign(payload, session)
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    token,
+		Domain:   m.opts.CookieDomain,
+		Path:     m.opts.CookiePath,
+		MaxAge:   int(m.opts.MaxAge / time.Second),
+		Secure:   m.opts.Secure,
+		HttpOnly: false, // 前端需读取Cookie并放入请求头
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, nil
+}
+
+func (m *CSRFMiddleware) verify(r *http.Request) error {
+	if !m.trustedOrigin(r) {
+		return ErrCSRFOriginDenied
+	}
+	cookie, err := r.Cookie(m.opts.CookieName)
+	if err != nil {
+		return ErrCSRFTokenMissing
+	}
+	token := r.Header.Get(m.opts.HeaderName)
+	if token == "" {
+		return ErrCSRFTokenMissing
+	}
+	if !hmac.Equal([]byte(token), []byte(cookie.Value)) || !m.validToken(token, m.opts.SessionID(r)) {
+		return ErrCSRFTokenInvalid
+	}
+	return nil
+}
+
+// validToken 校验令牌签名（绑定会话）与有效期，令牌格式：nonce.过期时间.签名
+func (m *CSRFMiddleware) validToken(token, session string) bool {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return false
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(m.sign(payload, session))) {
+		return false
+	}
+	j := strings.LastIndex(payload, ".")
+	if j < 0 {
+		return false
+	}
+	return time.Now().Unix() < int64(htxp.StringToInt(payload[j+1:]))
+}
+
+func (m *CSRFMiddleware) sign(payload, session string) string {
+	mac := hmac.New(sha256.New, []byte(m.opts.Secret))
+	mac.Write([]byte(payload))
+	mac.Write([]byte{0})
+	mac.Write([]byte(session))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cookieSessionID 以登录Cookie的值作为会话标识，未配置或未携带时为空（匿名会话）
+func cookieSessionID(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if name == "" {
+			return ""
+		}
+		if cookie, err := r.Cookie(name); err == nil {
+			return cookie.Value
+		}
+		return ""
+	}
+}
+
+// trustedOrigin 校验 Origin（缺失时使用 Referer）是否可信
+func (m *CSRFMiddleware) trustedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer, err := url.Parse(r.Header.Get("Referer"))
+		if err != nil || referer.Host == "" {
+			return false
+		}
+		origin = referer.Scheme + "://" + referer.Host
+	}
+	if len(m.opts.TrustedOrigins) > 0 {
+		return htxp.InArray(origin, m.opts.TrustedOrigins)
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
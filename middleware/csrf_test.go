@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+)
+
+const testCSRFSecret = "0123456789abcdef0123456789abcdef"
+
+// csrfToken 构造指定会话与过期时间的令牌
+func csrfToken(m *CSRFMiddleware, session string, expires time.Time) string {
+	payload := "nonce." + htxp.FormatUnixToString(expires.Unix())
+	return payload + "." + m.sign(payload, session)
+}
+
+func TestCSRFValidToken(t *testing.T) {
+	m := NewCSRFMiddleware(CSRFOptions{Secret: testCSRFSecret, AuthCookie: "session"})
+	valid := csrfToken(m, "alice", time.Now().Add(time.Hour))
+	other := NewCSRFMiddleware(CSRFOptions{Secret: strings.Repeat("x", 32), AuthCookie: "session"})
+
+	cases := []struct {
+		name    string
+		token   string
+		session string
+		want    bool
+	}{
+		{"valid", valid, "alice", true},
+		{"replayed across sessions", valid, "bob", false},
+		{"replayed without session", valid, "", false},
+		{"expired", csrfToken(m, "alice", time.Now().Add(-time.Second)), "alice", false},
+		{"expiry extended", strings.Replace(valid, ".", ".9", 1), "alice", false},
+		{"other secret", csrfToken(other, "alice", time.Now().Add(time.Hour)), "alice", false},
+		{"no signature", "nonce", "alice", false},
+		{"empty", "", "alice", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := m.validToken(c.token, c.session); got != c.want {
+				t.Fatalf("validToken = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCSRFTrustedOrigin(t *testing.T) {
+	sameHost := NewCSRFMiddleware(CSRFOptions{Secret: testCSRFSecret})
+	listed := NewCSRFMiddleware(CSRFOptions{Secret: testCSRFSecret, TrustedOrigins: []string{"https://app.example.com"}})
+
+	cases := []struct {
+		name    string
+		m       *CSRFMiddleware
+		origin  string
+		referer string
+		want    bool
+	}{
+		{"same host origin", sameHost, "https://api.example.com", "", true},
+		{"cross origin", sameHost, "https://evil.example.com", "", false},
+		{"referer fallback", sameHost, "", "https://api.example.com/page", true},
+		{"cross referer", sameHost, "", "https://evil.example.com/page", false},
+		{"no origin or referer", sameHost, "", "", false},
+		{"null origin", sameHost, "null", "", false},
+		{"listed origin", listed, "https://app.example.com", "", true},
+		{"listed ignores host", listed, "https://api.example.com", "", false},
+		{"origin prefix", listed, "https://app.example.com.evil.com", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "https://api.example.com/orders", nil)
+			if c.origin != "" {
+				r.Header.Set("Origin", c.origin)
+			}
+			if c.referer != "" {
+				r.Header.Set("Referer", c.referer)
+			}
+			if got := c.m.trustedOrigin(r); got != c.want {
+				t.Fatalf("trustedOrigin = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCSRFHandleRejectsReplayedToken(t *testing.T) {
+	m := NewCSRFMiddleware(CSRFOptions{Secret: testCSRFSecret, AuthCookie: "session"})
+	handler := m.Handle(func(w http.ResponseWriter, r *http.Request) {})
+
+	post := func(session, token string) int {
+		r := httptest.NewRequest(http.MethodPost, "https://api.example.com/orders", nil)
+		r.Header.Set("Origin", "https://api.example.com")
+		r.AddCookie(&http.Cookie{Name: "session", Value: session})
+		r.AddCookie(&http.Cookie{Name: "csrf_token", Value: token})
+		r.Header.Set("X-CSRF-Token", token)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Body.Len() == 0 {
+			return 0
+		}
+		var body htxp.Body
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Code
+	}
+
+	token := csrfToken(m, "alice", time.Now().Add(time.Hour))
+	if code := post("alice", token); code != 0 {
+		t.Fatalf("own session: code = %d", code)
+	}
+	if code := post("bob", token); code != CodeCSRFTokenInvalid {
+		t.Fatalf("other session: code = %d, want %d", code, CodeCSRFTokenInvalid)
+	}
+	expired := csrfToken(m, "alice", time.Now().Add(-time.Minute))
+	if code := post("alice", expired); code != CodeCSRFTokenInvalid {
+		t.Fatalf("expired: code = %d, want %d", code, CodeCSRFTokenInvalid)
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/linktomarkdown/htxp"
+)
+
+// 认证失败子码（写入 Body.Code），前端据此区分“刷新Token”与“重新登录”
+// 子码除以100即为对应的HTTP状态码
+const (
+	CodeTokenMissing          = 40101 // 未携带Token：重新登录
+	CodeTokenMalformed        = 40102 // Token格式错误：重新登录
+	CodeTokenExpired          = 40103 // Token已过期：使用刷新令牌换取新Token
+	CodeTokenSignatureInvalid = 40104 // 签名无效：重新登录
+	CodeTokenAlgorithmInvalid = 40105 // 签名算法或密钥不被接受：重新登录
+	CodeTokenNotValidYet      = 40106 // Token尚未生效（时钟偏差）：稍后重试
+	CodeTokenRevoked          = 40107 // Token已被吊销：重新登录
+	CodeTokenOutdated         = 40108 // Token版本过期（权限变更）：使用刷新令牌换取新Token
+	CodeTokenClaimsInvalid    = 40109 // 签发方、受众等声明不匹配：重新登录
+	CodeUserUnavailable       = 40110 // 用户信息加载失败（如用户已停用）：重新登录
+	CodeAuthUnavailable       = 50301 // 认证依赖的服务不可用：稍后重试
+)
+
+var (
+	// ErrTokenMissing 未携带Token
+	ErrTokenMissing = htxp.RegisterCode(CodeTokenMissing, 401, "auth.token_missing", "token required")
+	// ErrTokenMalformed Token格式错误
+	ErrTokenMalformed = htxp.RegisterCode(CodeTokenMalformed, 401, "auth.token_malformed", "token malformed")
+	// ErrTokenExpired Token已过期
+	ErrTokenExpired = htxp.RegisterCode(CodeTokenExpired, 401, "auth.token_expired", "token expired")
+	// ErrTokenSignatureInvalid Token签名无效
+	ErrTokenSignatureInvalid = htxp.RegisterCode(CodeTokenSignatureInvalid, 401, "auth.token_signature_invalid", "token signature invalid")
+	// ErrTokenAlgorithmInvalid Token签名算法或 kid 不被接受
+	ErrTokenAlgorithmInvalid = htxp.RegisterCode(CodeTokenAlgorithmInvalid, 401, "auth.token_algorithm_invalid", "token algorithm invalid")
+	// ErrTokenNotValidYet Token尚未生效
+	ErrTokenNotValidYet = htxp.RegisterCode(CodeTokenNotValidYet, 401, "auth.token_not_valid_yet", "token not valid yet")
+	// ErrTokenRevoked Token已被吊销（黑名单）
+	ErrTokenRevoked = htxp.RegisterCode(CodeTokenRevoked, 401, "auth.token_revoked", "token revoked")
+	// ErrTokenOutdated Token版本号已过期（用户注销或权限变更）
+	ErrTokenOutdated = htxp.RegisterCode(CodeTokenOutdated, 401, "auth.token_outdated", "token outdated, please refresh")
+	// ErrTokenClaimsInvalid Token声明校验失败
+	ErrTokenClaimsInvalid = htxp.RegisterCode(CodeTokenClaimsInvalid, 401, "auth.token_claims_invalid", "token claims invalid")
+	// ErrUserUnavailable 用户信息加载失败
+	ErrUserUnavailable = htxp.RegisterCode(CodeUserUnavailable, 401, "auth.user_unavailable", "user unavailable")
+	// ErrAuthUnavailable 认证依赖的服务不可用
+	ErrAuthUnavailable = htxp.RegisterCode(CodeAuthUnavailable, 503, "auth.service_unavailable", "service unavailable")
+)
+
+var authErrorCodes = map[error]int{
+	ErrTokenMissing:          CodeTokenMissing,
+	ErrTokenMalformed:        CodeTokenMalformed,
+	ErrTokenExpired:          CodeTokenExpired,
+	ErrTokenSignatureInvalid: CodeTokenSignatureInvalid,
+	ErrTokenAlgorithmInvalid: CodeTokenAlgorithmInvalid,
+	ErrTokenNotValidYet:      CodeTokenNotValidYet,
+	ErrTokenRevoked:          CodeTokenRevoked,
+	ErrTokenOutdated:         CodeTokenOutdated,
+	ErrTokenClaimsInvalid:    CodeTokenClaimsInvalid,
+	ErrUserUnavailable:       CodeUserUnavailable,
+	ErrAuthUnavailable:       CodeAuthUnavailable,
+}
+
+// ClassifyTokenError 将 jwt/v4 的校验错误映射为本包的哨兵错误
+func ClassifyTokenError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrTokenAlgorithmInvalid):
+		return ErrTokenAlgorithmInvalid
+	case errors.Is(err, htxp.ErrPasetoMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, htxp.ErrPasetoInvalid):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, htxp.ErrJWEMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, htxp.ErrJWEInvalid):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, htxp.ErrJWEUnsupported):
+		return ErrTokenAlgorithmInvalid
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenAlgorithmInvalid
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer), errors.Is(err, jwt.ErrTokenInvalidAudience),
+		errors.Is(err, jwt.ErrTokenInvalidId), errors.Is(err, jwt.ErrTokenInvalidClaims):
+		return ErrTokenClaimsInvalid
+	}
+	for sentinel := range authErrorCodes {
+		if errors.Is(err, sentinel) {
+			return sentinel
+		}
+	}
+	return ErrTokenMalformed
+}
+
+// AuthErrorCode 获取认证错误对应的子码
+func AuthErrorCode(err error) int {
+	if code, ok := authErrorCodes[ClassifyTokenError(err)]; ok {
+		return code
+	}
+	return http.StatusUnauthorized
+}
+
+// writeAuthError 以标准 Body 格式返回认证错误
+func writeAuthError(ctx context.Context, w http.ResponseWriter, err error) {
+	err = ClassifyTokenError(err)
+	htxp.ErrorWithCodeCtx(ctx, w, err, AuthErrorCode(err))
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/linktomarkdown/htxp"
+)
+
+func TestMiddlewareErrorsDistinct(t *testing.T) {
+	sentinels := []*htxp.CodedError{
+		ErrTokenMissing, ErrTokenExpired, ErrTokenRevoked, ErrTokenOutdated, ErrAuthUnavailable,
+		ErrCSRFOriginDenied, ErrCSRFTokenMissing, ErrCSRFTokenInvalid,
+		errSignatureHeadersRequired, errSignatureAPIKeyInvalid, errSignatureInvalid, errSignatureReplayed,
+		errTenantRequired, errCrossTenantDenied,
+		htxp.ErrUnauthorized, htxp.ErrForbidden,
+	}
+	for i, a := range sentinels {
+		if e, ok := htxp.LookupCode(a.Code); !ok || e != a {
+			t.Errorf("%s (%d) is not registered", a.Key, a.Code)
+		}
+		if msg, _ := htxp.DefaultCatalog.Lookup(htxp.LocaleEn, a.Key); a.Msg != msg {
+			t.Errorf("%s: Msg = %q, want %q", a.Key, a.Msg, msg)
+		}
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("errors.Is(%s, %s) = true", a.Key, b.Key)
+			}
+		}
+	}
+}
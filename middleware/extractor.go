@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenSourceKind 令牌来源类型
+type TokenSourceKind string
+
+const (
+	// TokenFromHeader 从请求头读取（如 Authorization: Bearer <token>）
+	TokenFromHeader TokenSourceKind = "header"
+	// TokenFromCookie 从指定名称的 Cookie 读取（适用于 HttpOnly Cookie）
+	TokenFromCookie TokenSourceKind = "cookie"
+	// TokenFromQuery 从查询参数读取（适用于 SSE 等无法设置请求头的场景）
+	TokenFromQuery TokenSourceKind = "query"
+	// TokenFromWebSocketProtocol 从 Sec-WebSocket-Protocol 读取
+	// 客户端以 new WebSocket(url, [Name, token]) 发送，令牌为紧随 Name 之后的值；
+	// 升级握手时服务端需回写 Sec-WebSocket-Protocol: Name
+	TokenFromWebSocketProtocol TokenSourceKind = "websocket"
+)
+
+// TokenSource 令牌来源
+type TokenSource struct {
+	Kind   TokenSourceKind
+	Name   string // 请求头名 / Cookie名 / 查询参数名 / WebSocket子协议标记
+	Scheme string // 令牌前缀（如 Bearer），为空表示值即令牌，仅对请求头生效
+}
+
+// DefaultTokenSources 默认仅从 Authorization: Bearer 请求头读取
+var DefaultTokenSources = []TokenSource{
+	{Kind: TokenFromHeader, Name: "Authorization", Scheme: "Bearer"},
+}
+
+// ExtractToken 按顺序从各来源提取令牌，返回第一个非空令牌
+// 未找到时返回 ErrTokenMissing，请求头前缀不匹配时返回 ErrTokenMalformed
+func ExtractToken(r *http.Request, sources []TokenSource) (string, error) {
+	if len(sources) == 0 {
+		sources = DefaultTokenSources
+	}
+	err := ErrTokenMissing
+	for _, source := range sources {
+		var token string
+		switch source.Kind {
+		case TokenFromHeader:
+			value := r.Header.Get(source.Name)
+			if value == "" {
+				continue
+			}
+			if source.Scheme != "" {
+				prefix := source.Scheme + " "
+				if !strings.HasPrefix(value, prefix) {
+					err = ErrTokenMalformed
+					continue
+				}
+				value = strings.TrimPrefix(value, prefix)
+			}
+			token = value
+		case TokenFromCookie:
+			if cookie, cerr := r.Cookie(source.Name); cerr == nil {
+				token = cookie.Value
+			}
+		case TokenFromQuery:
+			token = r.URL.Query().Get(source.Name)
+		case TokenFromWebSocketProtocol:
+			token = websocketProtocolToken(r, source.Name)
+		}
+		if token = strings.TrimSpace(token); token != "" {
+			return token, nil
+		}
+	}
+	return "", err
+}
+
+// websocketProtocolToken 从 Sec-WebSocket-Protocol 中取出紧随标记之后的值
+func websocketProtocolToken(r *http.Request, marker string) string {
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(p))
+		}
+	}
+	for i := 0; i < len(protocols)-1; i++ {
+		if protocols[i] == marker {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// metadataAuthorization gRPC metadata 中携带Token的key
+const metadataAuthorization = "authorization"
+
+// UnaryServerInterceptor zrpc一元调用认证拦截器，与 Handle 使用相同的校验逻辑并写入相同的context key
+// publicMethods: 无需认证的方法，支持glob，如 /user.User/Login、/user.Public/*
+func (m *AuthGuardMiddleware) UnaryServerInterceptor(publicMethods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if matchMethods(publicMethods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := m.authenticateIncoming(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor zrpc流式调用认证拦截器
+func (m *AuthGuardMiddleware) StreamServerInterceptor(publicMethods ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matchMethods(publicMethods, info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := m.authenticateIncoming(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor zrpc客户端拦截器，将调用方context中的Token透传给下游服务
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(forwardToken(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor zrpc流式客户端拦截器，将调用方context中的Token透传给下游服务
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(forwardToken(ctx), desc, cc, method, opts...)
+	}
+}
+
+// authenticateIncoming 从metadata读取Token并校验，返回写入用户信息的context
+func (m *AuthGuardMiddleware) authenticateIncoming(ctx context.Context, fullMethod string) (context.Context, error) {
+	token := incomingToken(ctx)
+	if token == "" {
+		return nil, authStatus(ErrTokenMissing)
+	}
+	principal, err := m.authenticate(ctx, token)
+	if err != nil {
+		return nil, authStatus(err)
+	}
+	ctx = context.WithValue(ctx, ContextKeyToken, token)
+	ctx = WithPrincipal(ctx, principal)
+	m.audit(ctx, principal, "grpc", fullMethod, peerAddr(ctx))
+	return ctx, nil
+}
+
+// authStatus 将认证错误转换为gRPC状态
+func authStatus(err error) error {
+	if errors.Is(err, ErrAuthUnavailable) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+func incomingToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(metadataAuthorization)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+}
+
+func forwardToken(ctx context.Context) context.Context {
+	token, ok := ctx.Value(ContextKeyToken).(string)
+	if !ok || token == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(metadataAuthorization)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, metadataAuthorization, "Bearer "+token)
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func matchMethods(patterns []string, fullMethod string) bool {
+	for _, pattern := range patterns {
+		if matchPath(pattern, fullMethod) {
+			return true
+		}
+	}
+	return false
+}
+
+// authServerStream 替换流的context
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// DefaultImpersonationMaxTTL 模拟登录Token的默认最长有效期
+const DefaultImpersonationMaxTTL = 30 * time.Minute
+
+// Impersonation 模拟登录信息（写入Token的 act 声明）
+type Impersonation = htxp.Impersonation
+
+// ImpersonationOptions 模拟登录Token签发配置
+type ImpersonationOptions struct {
+	MaxTTL       time.Duration // 最长有效期，默认 DefaultImpersonationMaxTTL
+	AllowedRoles []string      // 模拟登录时保留的角色（与被模拟用户角色取交集），为空表示不保留任何角色
+	Reason       string        // 模拟登录原因
+}
+
+// AuditEvent 模拟登录审计事件
+type AuditEvent struct {
+	ActorID   int64     `json:"actorId"`
+	SubjectID int64     `json:"subjectId"`
+	TenantID  string    `json:"tenantId,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	TokenID   string    `json:"tokenId,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ClientIP  string    `json:"clientIp,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// AuditLogger 审计事件记录器（可写入日志、数据库或消息队列）
+type AuditLogger func(ctx context.Context, event AuditEvent)
+
+// LogAuditEvent 默认审计记录器，写入 logx
+func LogAuditEvent(ctx context.Context, event AuditEvent) {
+	logx.WithContext(ctx).Infow("模拟登录审计",
+		logx.Field("actorId", event.ActorID),
+		logx.Field("subjectId", event.SubjectID),
+		logx.Field("tenantId", event.TenantID),
+		logx.Field("reason", event.Reason),
+		logx.Field("tokenId", event.TokenID),
+		logx.Field("method", event.Method),
+		logx.Field("path", event.Path),
+		logx.Field("clientIp", event.ClientIP),
+	)
+}
+
+// IssueImpersonationToken 为实际操作人签发模拟目标用户的Token（HS256）
+// claims 为被模拟用户的声明，有效期不超过 MaxTTL，角色限定为 AllowedRoles
+func IssueImpersonationToken(rds *redis.Redis, secretKey string, iat, seconds int64, actorID int64,
+	claims *JWTClaims, opts ImpersonationOptions) (string, error) {
+	seconds = impersonate(seconds, actorID, claims, opts)
+	return IssueToken(rds, secretKey, iat, seconds, claims)
+}
+
+// IssueImpersonationTokenWithKeySet 使用密钥集签发模拟登录Token
+func IssueImpersonationTokenWithKeySet(rds *redis.Redis, keySet *htxp.KeySet, iat, seconds int64, actorID int64,
+	claims *JWTClaims, opts ImpersonationOptions) (string, error) {
+	seconds = impersonate(seconds, actorID, claims, opts)
+	return IssueTokenWithKeySet(rds, keySet, iat, seconds, claims)
+}
+
+// impersonate 写入操作人并限制角色，返回限制后的有效期
+func impersonate(seconds, actorID int64, claims *JWTClaims, opts ImpersonationOptions) int64 {
+	maxTTL := opts.MaxTTL
+	if maxTTL <= 0 {
+		maxTTL = DefaultImpersonationMaxTTL
+	}
+	if limit := int64(maxTTL / time.Second); seconds <= 0 || seconds > limit {
+		seconds = limit
+	}
+	claims.Act = &Impersonation{ActorID: actorID, Reason: opts.Reason}
+	claims.Roles = intersectRoles(claims.Roles, opts.AllowedRoles)
+	return seconds
+}
+
+func intersectRoles(roles, allowed []string) []string {
+	var result []string
+	for _, role := range roles {
+		if htxp.InArray(role, allowed) {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
+// ImpersonatorFromContext 获取模拟登录的实际操作人，非模拟登录时返回 false
+func ImpersonatorFromContext(ctx context.Context) (*Impersonation, bool) {
+	p, ok := UserFromContext(ctx)
+	if !ok || p.Impersonation == nil {
+		return nil, false
+	}
+	return p.Impersonation, true
+}
+
+// audit 记录模拟登录请求
+func (m *AuthGuardMiddleware) audit(ctx context.Context, p *Principal, method, path, clientIP string) {
+	if p.Impersonation == nil {
+		return
+	}
+	logger := m.opts.AuditLogger
+	if logger == nil {
+		logger = LogAuditEvent
+	}
+	logger(ctx, AuditEvent{
+		ActorID:   p.Impersonation.ActorID,
+		SubjectID: p.UserID,
+		TenantID:  p.TenantID,
+		Reason:    p.Impersonation.Reason,
+		TokenID:   p.TokenID,
+		Method:    method,
+		Path:      path,
+		ClientIP:  clientIP,
+		Time:      time.Now(),
+	})
+}
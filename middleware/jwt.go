@@ -1,31 +1,124 @@
 package middleware
 
 import (
+	"crypto/ed25519"
+	"strings"
+	"time"
+
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/linktomarkdown/htxp"
 )
 
-// JWTClaims JWT声明结构（增强版，包含版本号）
-type JWTClaims struct {
-	UserID  int64   `json:"user_id"`
-	Version int64   `json:"version"` // Token版本号，用于权限变更时使旧Token失效
-	Roles   []string `json:"roles,omitempty"` // 角色列表（可选，减少RPC调用）
-	jwt.RegisteredClaims
+// JWTClaims JWT声明结构（增强版，包含版本号），与 htxp.GenTokenFor 签发的声明一致
+type JWTClaims = htxp.Claims
+
+// ValidatorOptions JWT声明校验配置
+type ValidatorOptions struct {
+	Issuer     string        // 期望的签发方（iss），为空不校验
+	Audience   string        // 本应用标识，Token的受众（aud）必须包含该值，为空不校验
+	Leeway     time.Duration // exp/nbf/iat 校验允许的时钟偏差
+	Algorithms []string      // 允许的签名算法（如 HS256、RS256、v4.local、v4.public），为空时由密钥类型决定
 }
 
 // ParseToken 解析JWT token（本地验证，无需RPC）
 func ParseToken(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+	return ParseTokenWithOptions(tokenString, SecretKeyfunc(secret), ValidatorOptions{})
+}
+
+// ParseTokenWithKeySet 使用公钥密钥集解析JWT token（按 kid 选择公钥，验签方无需持有私钥）
+func ParseTokenWithKeySet(tokenString string, keySet *htxp.KeySet) (*JWTClaims, error) {
+	return ParseTokenWithOptions(tokenString, keySet.Keyfunc, ValidatorOptions{})
+}
+
+// SecretKeyfunc 共享密钥的 jwt.Keyfunc，仅接受HMAC签名算法；密钥为空时拒绝所有Token
+func SecretKeyfunc(secret string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if secret == "" {
+			return nil, ErrTokenAlgorithmInvalid
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrTokenAlgorithmInvalid
+		}
 		return []byte(secret), nil
+	}
+}
+
+// ParseTokenWithOptions 解析JWT token并按配置严格校验签名算法、签发方、受众与时间声明
+func ParseTokenWithOptions(tokenString string, keyFunc jwt.Keyfunc, opts ValidatorOptions) (*JWTClaims, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if len(opts.Algorithms) > 0 && !htxp.InArray(token.Method.Alg(), opts.Algorithms) {
+			return nil, ErrTokenAlgorithmInvalid
+		}
+		return keyFunc(token)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	if err = opts.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ParsePaseto 解析 PASETO v4 令牌并按配置校验声明；未配置对应密钥的格式一律拒绝
+// opts.Algorithms 非空时须包含 htxp.PasetoAlgV4Local / htxp.PasetoAlgV4Public 才接受对应格式
+func ParsePaseto(tokenString string, localKey []byte, publicKey ed25519.PublicKey, opts ValidatorOptions) (*JWTClaims, error) {
+	alg := htxp.PasetoAlgV4Public
+	if strings.HasPrefix(tokenString, htxp.PasetoV4Local) {
+		alg = htxp.PasetoAlgV4Local
+	}
+	if len(opts.Algorithms) > 0 && !htxp.InArray(alg, opts.Algorithms) {
+		return nil, ErrTokenAlgorithmInvalid
+	}
+
+	var payload []byte
+	var err error
+	switch {
+	case strings.HasPrefix(tokenString, htxp.PasetoV4Local) && len(localKey) > 0:
+		payload, _, err = htxp.DecryptPasetoLocal(localKey, tokenString)
+	case strings.HasPrefix(tokenString, htxp.PasetoV4Public) && len(publicKey) > 0:
+		payload, _, err = htxp.VerifyPasetoPublic(publicKey, tokenString)
+	default:
+		return nil, ErrTokenAlgorithmInvalid
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, jwt.ErrSignatureInvalid
+	claims := &JWTClaims{}
+	if err = htxp.UnmarshalPasetoClaims(payload, claims); err != nil {
+		return nil, err
+	}
+	if err = opts.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
+// validate 校验声明，错误为 *jwt.ValidationError 以兼容 jwt/v4 的错误判断
+func (o ValidatorOptions) validate(claims *JWTClaims) error {
+	now := time.Now()
+	if claims.ExpiresAt != nil && now.After(claims.ExpiresAt.Add(o.Leeway)) {
+		return &jwt.ValidationError{Inner: ErrTokenExpired, Errors: jwt.ValidationErrorExpired}
+	}
+	if claims.NotBefore != nil && now.Add(o.Leeway).Before(claims.NotBefore.Time) {
+		return &jwt.ValidationError{Inner: ErrTokenNotValidYet, Errors: jwt.ValidationErrorNotValidYet}
+	}
+	if claims.IssuedAt != nil && now.Add(o.Leeway).Before(claims.IssuedAt.Time) {
+		return &jwt.ValidationError{Inner: ErrTokenNotValidYet, Errors: jwt.ValidationErrorIssuedAt}
+	}
+	if o.Issuer != "" && !claims.VerifyIssuer(o.Issuer, true) {
+		return &jwt.ValidationError{Inner: ErrTokenClaimsInvalid, Errors: jwt.ValidationErrorIssuer}
+	}
+	if o.Audience != "" && !claims.VerifyAudience(o.Audience, true) {
+		return &jwt.ValidationError{Inner: ErrTokenClaimsInvalid, Errors: jwt.ValidationErrorAudience}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+)
+
+func TestParsePasetoAlgorithms(t *testing.T) {
+	localKey, err := htxp.GeneratePasetoLocalKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iat := time.Now().Unix()
+	local, err := htxp.GenPasetoLocal(localKey, iat, 60, &JWTClaims{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := htxp.GenPasetoPublic(privateKey, iat, 60, &JWTClaims{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		token      string
+		algorithms []string
+		wantErr    error
+	}{
+		{"local unpinned", local, nil, nil},
+		{"public unpinned", public, nil, nil},
+		{"local pinned to EdDSA", local, []string{"EdDSA"}, ErrTokenAlgorithmInvalid},
+		{"public pinned to EdDSA", public, []string{"EdDSA"}, ErrTokenAlgorithmInvalid},
+		{"local pinned to v4.public", local, []string{htxp.PasetoAlgV4Public}, ErrTokenAlgorithmInvalid},
+		{"local pinned to v4.local", local, []string{htxp.PasetoAlgV4Local}, nil},
+		{"public pinned to v4.public", public, []string{htxp.PasetoAlgV4Public}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			claims, err := ParsePaseto(c.token, localKey, publicKey, ValidatorOptions{Algorithms: c.algorithms})
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("err = %v, want %v", err, c.wantErr)
+			}
+			if err == nil && claims.UserID != 1 {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/linktomarkdown/htxp"
+)
+
+// LocaleOptions 语言协商配置
+type LocaleOptions struct {
+	QueryParam    string // 显式指定语言的查询参数（如 lang），优先于 Accept-Language
+	DefaultLocale string // 无法协商时使用的语言，为空时保持各错误的默认消息
+}
+
+// LocaleMiddleware 根据 Accept-Language 在 htxp.DefaultCatalog 中选择语言并写入context，供 htxp.ResponseCtx 翻译消息
+// 应放在其他中间件之前注册，使认证等中间件的错误同样按语言返回
+type LocaleMiddleware struct {
+	opts LocaleOptions
+}
+
+// NewLocaleMiddleware 创建语言协商中间件
+func NewLocaleMiddleware(opts LocaleOptions) *LocaleMiddleware {
+	return &LocaleMiddleware{opts: opts}
+}
+
+// Handle 处理HTTP请求，协商语言
+func (m *LocaleMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		var locale string
+		if m.opts.QueryParam != "" {
+			locale = htxp.DefaultCatalog.Match(r.URL.Query().Get(m.opts.QueryParam))
+		}
+		if locale == "" {
+			locale = htxp.DefaultCatalog.Match(r.Header.Get("Accept-Language"))
+		}
+		if locale == "" {
+			locale = m.opts.DefaultLocale
+		}
+		if locale == "" {
+			next(w, r)
+			return
+		}
+		next(w, r.WithContext(htxp.WithLocale(r.Context(), locale)))
+	}
+}
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// OAuth2 令牌类型提示（token_type_hint）
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// OAuthOptions 令牌自省（RFC 7662）与吊销（RFC 7009）端点配置
+type OAuthOptions struct {
+	Guard         *AuthGuardMiddleware    // 访问令牌的验签、黑名单与版本检查（与网关守卫配置一致）
+	RefreshTokens *htxp.RefreshTokenStore // 刷新令牌存储，为空时仅支持访问令牌
+	Clients       APIKeyStore             // 调用方凭证（client_id -> client_secret）
+}
+
+// mustValidate 校验必填配置，缺少访问令牌守卫时 panic
+func (o OAuthOptions) mustValidate() {
+	if o.Guard == nil {
+		panic("htxp: oauth options require a Guard")
+	}
+}
+
+// IntrospectionResponse 令牌自省响应（RFC 7662 第2.2节）
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"token_type,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	UserID    int64    `json:"user_id,omitempty"`
+	TenantID  string   `json:"tenant_id,omitempty"`
+	DeviceID  string   `json:"device_id,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ActorID   int64    `json:"actor_id,omitempty"`
+}
+
+// oauthError OAuth2 错误响应（RFC 6749 第5.2节）
+type oauthError struct {
+	Error string `json:"error"`
+}
+
+// IntrospectionHandler 令牌自省端点，供非Go服务校验令牌（含黑名单与版本号）
+type IntrospectionHandler struct {
+	opts OAuthOptions
+}
+
+// NewIntrospectionHandler 创建令牌自省端点
+func NewIntrospectionHandler(opts OAuthOptions) *IntrospectionHandler {
+	opts.mustValidate()
+	return &IntrospectionHandler{opts: opts}
+}
+
+// ServeHTTP 处理自省请求：POST application/x-www-form-urlencoded，参数 token、token_type_hint
+func (h *IntrospectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, hint, ok := readOAuthRequest(w, r, h.opts.Clients)
+	if !ok {
+		return
+	}
+
+	resp, err := h.introspect(r.Context(), token, hint)
+	if err != nil {
+		logx.Errorf("令牌自省失败: %v", err)
+		httpx.WriteJson(w, http.StatusServiceUnavailable, oauthError{Error: "temporarily_unavailable"})
+		return
+	}
+	httpx.OkJson(w, resp)
+}
+
+// introspect 按提示优先查询对应类型，未命中时继续尝试另一类型；无效令牌返回 active=false
+func (h *IntrospectionHandler) introspect(ctx context.Context, token, hint string) (*IntrospectionResponse, error) {
+	if hint == TokenTypeHintRefreshToken {
+		if resp, err := h.introspectRefreshToken(ctx, token); resp != nil || err != nil {
+			return resp, err
+		}
+		return h.introspectAccessToken(ctx, token)
+	}
+	if resp, err := h.introspectAccessToken(ctx, token); err != nil || resp.Active {
+		return resp, err
+	}
+	if resp, err := h.introspectRefreshToken(ctx, token); resp != nil || err != nil {
+		return resp, err
+	}
+	return &IntrospectionResponse{}, nil
+}
+
+func (h *IntrospectionHandler) introspectAccessToken(ctx context.Context, token string) (*IntrospectionResponse, error) {
+	claims, err := h.opts.Guard.verify(ctx, token)
+	if errors.Is(err, ErrAuthUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return &IntrospectionResponse{}, nil
+	}
+
+	resp := &IntrospectionResponse{
+		Active:    true,
+		TokenType: TokenTypeHintAccessToken,
+		Sub:       claims.Subject,
+		Jti:       claims.ID,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+		UserID:    claims.UserID,
+		TenantID:  claims.TenantID,
+		DeviceID:  claims.DeviceID,
+		Roles:     claims.Roles,
+	}
+	if resp.Sub == "" && claims.UserID != 0 {
+		resp.Sub = strconv.FormatInt(claims.UserID, 10)
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.Act != nil {
+		resp.ActorID = claims.Act.ActorID
+	}
+	return resp, nil
+}
+
+// introspectRefreshToken 刷新令牌不存在时返回 nil, nil
+func (h *IntrospectionHandler) introspectRefreshToken(ctx context.Context, token string) (*IntrospectionResponse, error) {
+	if h.opts.RefreshTokens == nil {
+		return nil, nil
+	}
+	rt, err := h.opts.RefreshTokens.Inspect(ctx, token)
+	switch {
+	case errors.Is(err, htxp.ErrRefreshTokenInvalid):
+		return nil, nil
+	case errors.Is(err, htxp.ErrRefreshTokenRevoked), errors.Is(err, htxp.ErrRefreshTokenReused):
+		return &IntrospectionResponse{}, nil
+	case err != nil:
+		return nil, err
+	}
+	return &IntrospectionResponse{
+		Active:    true,
+		TokenType: TokenTypeHintRefreshToken,
+		Sub:       strconv.FormatInt(rt.UserID, 10),
+		Exp:       rt.ExpiresAt.Unix(),
+		UserID:    rt.UserID,
+		DeviceID:  rt.DeviceID,
+	}, nil
+}
+
+// RevocationHandler 令牌吊销端点，访问令牌加入黑名单，刷新令牌吊销整个令牌族
+type RevocationHandler struct {
+	opts OAuthOptions
+}
+
+// NewRevocationHandler 创建令牌吊销端点
+func NewRevocationHandler(opts OAuthOptions) *RevocationHandler {
+	opts.mustValidate()
+	return &RevocationHandler{opts: opts}
+}
+
+// ServeHTTP 处理吊销请求：POST application/x-www-form-urlencoded，参数 token、token_type_hint
+// 按 RFC 7009 第2.2节，令牌无效、已过期、已吊销或无法吊销（缺少 jti）时同样返回 200，仅存储故障返回 503
+func (h *RevocationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, hint, ok := readOAuthRequest(w, r, h.opts.Clients)
+	if !ok {
+		return
+	}
+
+	if err := h.revoke(r.Context(), token, hint); err != nil {
+		logx.Errorf("令牌吊销失败: %v", err)
+		httpx.WriteJson(w, http.StatusServiceUnavailable, oauthError{Error: "temporarily_unavailable"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *RevocationHandler) revoke(ctx context.Context, token, hint string) error {
+	if hint != TokenTypeHintRefreshToken {
+		if claims, err := h.opts.Guard.parseToken(token); err == nil {
+			if claims.ID == "" || claims.ExpiresAt == nil {
+				// 没有 jti 或 exp 的令牌无法加入黑名单，按 RFC 7009 视为吊销成功
+				logx.Infof("令牌缺少 jti 或 exp，跳过吊销: userID=%d", claims.UserID)
+				return nil
+			}
+			return BlacklistToken(ctx, h.opts.Guard.redis, claims.ID, claims.ExpiresAt.Time)
+		}
+	}
+	if h.opts.RefreshTokens == nil {
+		return nil
+	}
+	return h.opts.RefreshTokens.Revoke(ctx, token)
+}
+
+// readOAuthRequest 校验请求方法与调用方凭证并读取参数，失败时已写入错误响应
+// 凭证支持 HTTP Basic 或表单 client_id/client_secret（RFC 6749 第2.3.1节）
+func readOAuthRequest(w http.ResponseWriter, r *http.Request, clients APIKeyStore) (token, hint string, ok bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		httpx.WriteJson(w, http.StatusMethodNotAllowed, oauthError{Error: "invalid_request"})
+		return "", "", false
+	}
+	if err := r.ParseForm(); err != nil {
+		httpx.WriteJson(w, http.StatusBadRequest, oauthError{Error: "invalid_request"})
+		return "", "", false
+	}
+
+	clientID, clientSecret, basic := r.BasicAuth()
+	if !basic {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if !verifyClient(r.Context(), clients, clientID, clientSecret) {
+		logx.Errorf("OAuth调用方认证失败: clientID=%s", clientID)
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		httpx.WriteJson(w, http.StatusUnauthorized, oauthError{Error: "invalid_client"})
+		return "", "", false
+	}
+
+	token = r.PostForm.Get("token")
+	if token == "" {
+		httpx.WriteJson(w, http.StatusBadRequest, oauthError{Error: "invalid_request"})
+		return "", "", false
+	}
+	return token, r.PostForm.Get("token_type_hint"), true
+}
+
+func verifyClient(ctx context.Context, clients APIKeyStore, clientID, clientSecret string) bool {
+	if clients == nil || clientID == "" || clientSecret == "" {
+		return false
+	}
+	secret, err := clients.Secret(ctx, clientID)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) == 1
+}
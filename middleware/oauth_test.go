@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestOAuthHandlersRequireGuard(t *testing.T) {
+	for name, newHandler := range map[string]func(OAuthOptions) http.Handler{
+		"introspection": func(o OAuthOptions) http.Handler { return NewIntrospectionHandler(o) },
+		"revocation":    func(o OAuthOptions) http.Handler { return NewRevocationHandler(o) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("nil Guard accepted")
+				}
+			}()
+			newHandler(OAuthOptions{})
+		})
+	}
+}
+
+func TestIntrospectionSubFallsBackToUserID(t *testing.T) {
+	const secret = "introspection-test-secret"
+	mr := miniredis.RunT(t)
+	handler := NewIntrospectionHandler(OAuthOptions{
+		Guard:   NewAuthGuardMiddleware(secret, redis.New(mr.Addr())),
+		Clients: StaticAPIKeys{"client": "client-secret"},
+	})
+
+	introspect := func(claims *JWTClaims) IntrospectionResponse {
+		t.Helper()
+		token, err := htxp.GenTokenFor(secret, time.Now().Unix(), 60, claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		form := url.Values{"token": {token}}
+		r := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.SetBasicAuth("client", "client-secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		var resp IntrospectionResponse
+		if err = json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("status %d: %v", w.Code, err)
+		}
+		return resp
+	}
+
+	if resp := introspect(&JWTClaims{UserID: 42, Version: 1}); !resp.Active || resp.Sub != "42" {
+		t.Fatalf("resp = %+v", resp)
+	}
+	claims := &JWTClaims{UserID: 42, Version: 1}
+	claims.Subject = "user-42"
+	if resp := introspect(claims); !resp.Active || resp.Sub != "user-42" {
+		t.Fatalf("resp = %+v", resp)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/linktomarkdown/htxp"
+)
+
+// AdminRole 管理员角色名，Token角色中包含该角色时 IsAdmin 为 true
+const AdminRole = "admin"
+
+// Principal 当前请求的调用者
+type Principal struct {
+	UserID             int64          `json:"userId"`
+	TenantID           string         `json:"tenantId,omitempty"`
+	Roles              []string       `json:"roles,omitempty"`
+	IsAdmin            bool           `json:"isAdmin"`
+	IsExtensionsVip    bool           `json:"isExtensionsVip"`
+	IsTutorialVip      bool           `json:"isTutorialVip"`
+	IsMonthlyVip       bool           `json:"isMonthlyVip"`
+	IsYearlyVip        bool           `json:"isYearlyVip"`
+	PurchasedLessonIds []int64        `json:"purchasedLessonIds,omitempty"`
+	UserInfo           interface{}    `json:"userInfo,omitempty"` // 用户完整信息（可选，由 PrincipalLoader 填充）
+	TokenID            string         `json:"tokenId,omitempty"`
+	Impersonation      *Impersonation `json:"impersonation,omitempty"` // 模拟登录时的实际操作人，正常登录为空
+}
+
+// PrincipalLoader 根据Token声明补全用户主体信息（如从缓存或RPC加载VIP、已购课程）
+// 传入的 principal 已包含Token中的用户ID与角色
+type PrincipalLoader func(ctx context.Context, claims *JWTClaims, principal *Principal) error
+
+// principalKey 存放 *Principal 的 context key
+const principalKey contextKey = "principal"
+
+// NewPrincipalFromClaims 根据Token声明创建用户主体
+func NewPrincipalFromClaims(claims *JWTClaims) *Principal {
+	return &Principal{
+		UserID:        claims.UserID,
+		TenantID:      claims.TenantID,
+		Roles:         claims.Roles,
+		IsAdmin:       htxp.InArray(AdminRole, claims.Roles),
+		TokenID:       claims.ID,
+		Impersonation: claims.Act,
+	}
+}
+
+// loadPrincipal 由Token声明创建用户主体，并交给 loader 补全
+func loadPrincipal(ctx context.Context, loader PrincipalLoader, claims *JWTClaims) (*Principal, error) {
+	principal := NewPrincipalFromClaims(claims)
+	if loader != nil {
+		if err := loader(ctx, claims, principal); err != nil {
+			return nil, err
+		}
+	}
+	return principal, nil
+}
+
+// WithPrincipal 将用户主体放到context，并同步设置 contextkeys.go 中声明的全部key
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey, p)
+	ctx = context.WithValue(ctx, ContextKeyUserID, p.UserID)
+	ctx = context.WithValue(ctx, ContextKeyTenantID, p.TenantID)
+	ctx = context.WithValue(ctx, ContextKeyRoles, p.Roles)
+	ctx = context.WithValue(ctx, ContextKeyIsAdmin, p.IsAdmin)
+	ctx = context.WithValue(ctx, ContextKeyIsExtensionsVip, p.IsExtensionsVip)
+	ctx = context.WithValue(ctx, ContextKeyIsTutorialVip, p.IsTutorialVip)
+	ctx = context.WithValue(ctx, ContextKeyIsMonthlyVip, p.IsMonthlyVip)
+	ctx = context.WithValue(ctx, ContextKeyIsYearlyVip, p.IsYearlyVip)
+	ctx = context.WithValue(ctx, ContextKeyPurchasedLessonIds, p.PurchasedLessonIds)
+	if p.UserInfo != nil {
+		ctx = context.WithValue(ctx, ContextKeyUserInfo, p.UserInfo)
+	}
+	if p.Impersonation != nil {
+		ctx = context.WithValue(ctx, ContextKeyImpersonatorID, p.Impersonation.ActorID)
+	}
+	return ctx
+}
+
+// UserFromContext 获取当前调用者（handler 与 logic 层统一使用）
+func UserFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*Principal)
+	return p, ok && p != nil
+}
+
+// HasPurchasedLesson 判断调用者是否已购买课程
+func (p *Principal) HasPurchasedLesson(lessonID int64) bool {
+	for _, id := range p.PurchasedLessonIds {
+		if id == lessonID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RateLimitAlgorithm 限流算法
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket 令牌桶：容量为 Limit，每 Window 补满一桶，允许突发
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow 滑动窗口：任意 Window 时长内最多 Limit 次请求
+	SlidingWindow
+)
+
+// RateLimitKeyFunc 限流维度，返回空字符串表示不限流
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitOptions 限流中间件配置
+type RateLimitOptions struct {
+	Cache      *htxp.CacheClient
+	Algorithm  RateLimitAlgorithm
+	Limit      int              // 令牌桶容量 / 窗口内最大请求数
+	Window     time.Duration    // 补满令牌桶的时长 / 滑动窗口长度
+	KeyFunc    RateLimitKeyFunc // 限流维度，默认 KeyByUserOrIP
+	Prefix     string           // Redis key 前缀，默认 ratelimit
+	FailClosed bool             // Redis不可用时拒绝请求，默认放行
+	// TrustedProxies 可信代理，仅采信这些代理转发的 X-Forwarded-For / X-Real-IP，为空时使用 RemoteAddr
+	TrustedProxies TrustedProxies
+}
+
+// RateLimitMiddleware 基于Redis的限流中间件（Lua脚本保证原子性，多实例共享配额）
+type RateLimitMiddleware struct {
+	opts RateLimitOptions
+}
+
+// tokenBucketScript 令牌桶
+// KEYS[1] 限流key; ARGV[1] 容量, ARGV[2] 补满整桶的毫秒数
+// 返回 {是否放行, 剩余令牌, 重试等待毫秒, 补满等待毫秒}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = capacity / tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or capacity
+local ts = tonumber(data[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], tonumber(ARGV[2]))
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+// slidingWindowScript 滑动窗口（有序集合记录窗口内请求）
+// KEYS[1] 限流key; ARGV[1] 窗口内最大请求数, ARGV[2] 窗口毫秒数, ARGV[3] 请求唯一标识
+// 返回 {是否放行, 剩余次数, 重试等待毫秒, 窗口重置毫秒}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, limit - count - 1, 0, window}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local retry = tonumber(oldest[2]) + window - now
+return {0, 0, retry, retry}
+`)
+
+// NewRateLimitMiddleware 创建限流中间件
+func NewRateLimitMiddleware(opts RateLimitOptions) *RateLimitMiddleware {
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = KeyByUserOrIP
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "ratelimit"
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 60
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	return &RateLimitMiddleware{opts: opts}
+}
+
+// Handle 处理HTTP请求，超出配额时返回 429
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r = withClientIP(r, m.opts.TrustedProxies)
+		key := m.opts.KeyFunc(r)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		allowed, remaining, retry, reset, err := m.take(r, fmt.Sprintf("%s:%s", m.opts.Prefix, key))
+		if err != nil {
+			logx.Errorf("限流检查失败: key=%s, err=%v", key, err)
+			if m.opts.FailClosed {
+				htxp.ErrorCtx(r.Context(), w, htxp.ErrServiceUnavailable)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(m.opts.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(reset), 10))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(retry), 10))
+			htxp.ErrorCtx(r.Context(), w, htxp.ErrTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// take 执行限流脚本，retry 与 reset 单位为毫秒
+func (m *RateLimitMiddleware) take(r *http.Request, key string) (allowed bool, remaining, retry, reset int64, err error) {
+	windowMs := m.opts.Window.Milliseconds()
+	var res []int64
+	switch m.opts.Algorithm {
+	case SlidingWindow:
+		res, err = slidingWindowScript.Run(r.Context(), m.opts.Cache, []string{key},
+			m.opts.Limit, windowMs, htxp.NewTokenID()).Int64Slice()
+	default:
+		res, err = tokenBucketScript.Run(r.Context(), m.opts.Cache, []string{key},
+			m.opts.Limit, windowMs).Int64Slice()
+	}
+	if err != nil {
+		return false, 0, 0, 0, err
+	}
+	return res[0] == 1, res[1], res[2], res[3], nil
+}
+
+func ceilSeconds(ms int64) int64 {
+	return int64(math.Ceil(float64(ms) / 1000))
+}
+
+// KeyByUserID 按用户限流（需在 AuthGuardMiddleware 之后使用），未登录时不限流
+func KeyByUserID(r *http.Request) string {
+	if p, ok := UserFromContext(r.Context()); ok {
+		return fmt.Sprintf("user:%d", p.UserID)
+	}
+	return ""
+}
+
+// KeyByIP 按客户端IP限流
+func KeyByIP(r *http.Request) string {
+	return "ip:" + ClientIP(r)
+}
+
+// KeyByUserOrIP 已登录按用户限流，否则按客户端IP限流
+func KeyByUserOrIP(r *http.Request) string {
+	if key := KeyByUserID(r); key != "" {
+		return key
+	}
+	return KeyByIP(r)
+}
+
+// KeyByAPIKey 按API Key限流（需在 SignatureMiddleware 之后使用）
+func KeyByAPIKey(r *http.Request) string {
+	if keyID, ok := APIKeyFromContext(r.Context()); ok {
+		return "apikey:" + keyID
+	}
+	return ""
+}
+
+// KeyByPath 在已有维度上叠加请求路径（如短信、登录接口单独计数）
+func KeyByPath(keyFunc RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		key := keyFunc(r)
+		if key == "" {
+			return ""
+		}
+		return key + ":" + r.URL.Path
+	}
+}
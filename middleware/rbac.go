@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// PermissionProvider 角色到权限的映射来源
+type PermissionProvider interface {
+	Permissions(ctx context.Context, role string) ([]string, error)
+}
+
+// StaticPermissions 静态角色权限映射，可直接从配置文件加载
+// 例如 {"admin": ["*"], "teacher": ["course:*", "lesson:read"]}
+type StaticPermissions map[string][]string
+
+// Permissions 获取角色的权限列表
+func (p StaticPermissions) Permissions(_ context.Context, role string) ([]string, error) {
+	return p[role], nil
+}
+
+// RedisPermissions 从Redis读取角色权限映射（Set类型，key: role:permissions:<role>）
+// 未开启本地缓存时，每次鉴权按用户的每个角色各查询一次Redis
+type RedisPermissions struct {
+	redis    *redis.Redis
+	cacheTTL time.Duration
+	mu       sync.RWMutex
+	entries  map[string]permissionEntry
+}
+
+type permissionEntry struct {
+	permissions []string
+	fetchedAt   time.Time
+}
+
+// NewRedisPermissions 创建基于Redis的角色权限映射（不缓存）
+func NewRedisPermissions(rds *redis.Redis) *RedisPermissions {
+	return NewCachedRedisPermissions(rds, 0)
+}
+
+// NewCachedRedisPermissions 创建带本地缓存的角色权限映射
+// cacheTTL: 缓存有效期，权限变更最长在该时长后生效；0 表示每次都查询Redis
+func NewCachedRedisPermissions(rds *redis.Redis, cacheTTL time.Duration) *RedisPermissions {
+	return &RedisPermissions{
+		redis:    rds,
+		cacheTTL: cacheTTL,
+		entries:  make(map[string]permissionEntry),
+	}
+}
+
+// Permissions 获取角色的权限列表
+func (p *RedisPermissions) Permissions(ctx context.Context, role string) ([]string, error) {
+	if p.cacheTTL > 0 {
+		p.mu.RLock()
+		entry, ok := p.entries[role]
+		p.mu.RUnlock()
+		if ok && time.Since(entry.fetchedAt) < p.cacheTTL {
+			return entry.permissions, nil
+		}
+	}
+
+	permissions, err := p.redis.SmembersCtx(ctx, fmt.Sprintf("role:permissions:%s", role))
+	if err != nil {
+		return nil, err
+	}
+	if p.cacheTTL > 0 {
+		p.mu.Lock()
+		p.entries[role] = permissionEntry{permissions: permissions, fetchedAt: time.Now()}
+		p.mu.Unlock()
+	}
+	return permissions, nil
+}
+
+// RolesFromContext 获取当前用户的角色列表（需在 AuthGuardMiddleware 之后使用）
+func RolesFromContext(ctx context.Context) []string {
+	if p, ok := UserFromContext(ctx); ok {
+		return p.Roles
+	}
+	return nil
+}
+
+// RequireRoles 要求用户同时拥有全部角色
+func RequireRoles(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return roleGuard(func(userRoles []string) bool {
+		for _, role := range roles {
+			if !htxp.InArray(role, userRoles) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// RequireAnyRole 要求用户拥有任一角色
+func RequireAnyRole(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return roleGuard(func(userRoles []string) bool {
+		for _, role := range roles {
+			if htxp.InArray(role, userRoles) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func roleGuard(allow func(userRoles []string) bool) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := UserFromContext(r.Context()); !ok {
+				writeAuthError(r.Context(), w, ErrTokenMissing)
+				return
+			}
+			if !allow(RolesFromContext(r.Context())) {
+				htxp.ErrorCtx(r.Context(), w, htxp.ErrForbidden)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// PermissionGuard 基于角色权限映射的鉴权中间件
+type PermissionGuard struct {
+	provider PermissionProvider
+}
+
+// NewPermissionGuard 创建权限守卫
+// provider: 角色权限映射（StaticPermissions 或 RedisPermissions）
+func NewPermissionGuard(provider PermissionProvider) *PermissionGuard {
+	return &PermissionGuard{provider: provider}
+}
+
+// RequirePermission 要求用户的角色合计拥有全部权限
+// 权限支持通配符：* 匹配所有权限，course:* 匹配 course:read 等
+// 权限来源出错时返回 ErrAuthUnavailable（503），权限不足返回 403
+func (g *PermissionGuard) RequirePermission(permissions ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := UserFromContext(r.Context()); !ok {
+				writeAuthError(r.Context(), w, ErrTokenMissing)
+				return
+			}
+			granted, err := g.grantedPermissions(r.Context(), RolesFromContext(r.Context()))
+			if err != nil {
+				logx.Errorf("获取角色权限失败: %v", err)
+				writeAuthError(r.Context(), w, ErrAuthUnavailable)
+				return
+			}
+			for _, permission := range permissions {
+				if !matchPermission(permission, granted) {
+					htxp.ErrorCtx(r.Context(), w, htxp.ErrForbidden)
+					return
+				}
+			}
+			next(w, r)
+		}
+	}
+}
+
+// HasPermission 判断当前用户是否拥有权限（供logic层使用）
+func (g *PermissionGuard) HasPermission(ctx context.Context, permission string) (bool, error) {
+	granted, err := g.grantedPermissions(ctx, RolesFromContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	return matchPermission(permission, granted), nil
+}
+
+func (g *PermissionGuard) grantedPermissions(ctx context.Context, roles []string) ([]string, error) {
+	var granted []string
+	for _, role := range roles {
+		permissions, err := g.provider.Permissions(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		granted = append(granted, permissions...)
+	}
+	return granted, nil
+}
+
+// matchPermission 判断所需权限是否被已授予的权限覆盖
+func matchPermission(required string, granted []string) bool {
+	for _, p := range granted {
+		if p == "*" || p == required {
+			return true
+		}
+		if strings.HasSuffix(p, ":*") && strings.HasPrefix(required, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestRequirePermissionStatus(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.SAdd("role:permissions:teacher", "course:*")
+	rds := redis.New(mr.Addr())
+	guard := NewPermissionGuard(NewCachedRedisPermissions(rds, time.Minute))
+
+	if code := servePermission(t, guard, "course:read"); code != 0 {
+		t.Fatalf("granted: code = %d", code)
+	}
+	if code := servePermission(t, guard, "lesson:write"); code != htxp.ErrForbidden.Code {
+		t.Fatalf("denied: code = %d", code)
+	}
+
+	// 命中本地缓存时不受Redis故障影响，未缓存时返回 503 而不是 403
+	mr.Close()
+	if code := servePermission(t, guard, "course:read"); code != 0 {
+		t.Fatalf("cached: code = %d", code)
+	}
+	uncached := NewPermissionGuard(NewRedisPermissions(rds))
+	if code := servePermission(t, uncached, "course:read"); code != CodeAuthUnavailable {
+		t.Fatalf("redis down: code = %d", code)
+	}
+}
+
+// servePermission 以 teacher 角色请求受保护的接口，返回响应体业务码（放行时为 0）
+func servePermission(t *testing.T, guard *PermissionGuard, permission string) int {
+	t.Helper()
+	handler := guard.RequirePermission(permission)(func(w http.ResponseWriter, r *http.Request) {})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(WithPrincipal(r.Context(), &Principal{UserID: 1, Roles: []string{"teacher"}}))
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Body.Len() == 0 {
+		return 0
+	}
+	var body htxp.Body
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Code
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// RouteRule 路由匹配规则
+type RouteRule struct {
+	Method string // 请求方法，为空或 * 表示任意方法
+	Path   string // 路径glob模式，如 /api/courses/*；以 /** 结尾时匹配该前缀下的所有路径
+}
+
+// MatchRoutes 判断请求是否匹配任一路由规则
+func MatchRoutes(rules []RouteRule, r *http.Request) bool {
+	for _, rule := range rules {
+		if rule.Method != "" && rule.Method != "*" && !strings.EqualFold(rule.Method, r.Method) {
+			continue
+		}
+		if matchPath(rule.Path, r.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath 按 path.Clean 规范化后的路径匹配（与 go-zero 路由分发一致）
+// 含 .. 路径段的请求一律不匹配，避免 /public/../admin 命中公开路由
+func matchPath(pattern, p string) bool {
+	if hasDotDotSegment(p) {
+		return false
+	}
+	p = path.Clean("/" + p)
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return p == prefix || strings.HasPrefix(p, prefix+"/")
+	}
+	matched, err := path.Match(pattern, p)
+	return err == nil && matched
+}
+
+func hasDotDotSegment(p string) bool {
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api/public/*", "/api/public/news", true},
+		{"/api/public/*", "/api/public/news/", true},
+		{"/api/public/*", "/api/public/news/1", false},
+		{"/api/public/*", "/api/public/../admin", false},
+		{"/api/public/*", "/api/public/..", false},
+		{"/api/public/*", "/api/public//news", true},
+		{"/api/public/*", "/api/public/./news", true},
+		{"/api/public/**", "/api/public", true},
+		{"/api/public/**", "/api/public/", true},
+		{"/api/public/**", "/api/public/a/b/c", true},
+		{"/api/public/**", "/api/publicity", false},
+		{"/api/public/**", "/api/public/a/../../admin", false},
+		{"/health", "/health/", true},
+		{"/health", "health", true},
+		{"/health", "/health/..", false},
+	}
+	for _, c := range cases {
+		if got := matchPath(c.pattern, c.path); got != c.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
+
+func TestMatchRoutes(t *testing.T) {
+	rules := []RouteRule{
+		{Method: http.MethodGet, Path: "/api/courses/*"},
+		{Method: "*", Path: "/api/public/**"},
+		{Path: "/health"},
+	}
+	cases := []struct {
+		method string
+		target string
+		want   bool
+	}{
+		{http.MethodGet, "/api/courses/1", true},
+		{"get", "/api/courses/1", true},
+		{http.MethodPost, "/api/courses/1", false},
+		{http.MethodGet, "/api/courses/1/", true},
+		{http.MethodGet, "/api/courses/../admin", false},
+		{http.MethodDelete, "/api/public/files/a.txt", true},
+		{http.MethodGet, "/api/public/../admin/users", false},
+		{http.MethodPut, "/health/", true},
+		{http.MethodGet, "/admin", false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Method = c.method
+		r.URL.Path = c.target
+		if got := MatchRoutes(rules, r); got != c.want {
+			t.Errorf("MatchRoutes(%s %s) = %v, want %v", c.method, c.target, got, c.want)
+		}
+	}
+}
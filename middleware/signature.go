@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 请求签名相关请求头
+const (
+	HeaderAPIKey    = "X-Api-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// ErrAPIKeyNotFound API Key不存在或已停用
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// 请求签名校验失败子码（写入 Body.Code），子码除以100即为对应的HTTP状态码
+const (
+	CodeSignatureHeadersRequired  = 40121 // 缺少签名请求头
+	CodeSignatureTimestampInvalid = 40122 // 时间戳格式错误
+	CodeSignatureTimestampExpired = 40123 // 时间戳已过期
+	CodeSignatureAPIKeyInvalid    = 40124 // API Key无效
+	CodeSignatureInvalid          = 40125 // 签名无效
+	CodeSignatureReplayed         = 40126 // 重复的请求
+	CodeSignatureBodyReadFailed   = 40021 // 读取请求体失败
+	CodeSignatureBodyTooLarge     = 41301 // 请求体过大
+)
+
+// 请求签名校验失败的错误
+var (
+	errSignatureHeadersRequired  = htxp.RegisterCode(CodeSignatureHeadersRequired, 401, "signature.headers_required", "signature headers required")
+	errSignatureTimestampInvalid = htxp.RegisterCode(CodeSignatureTimestampInvalid, 401, "signature.timestamp_invalid", "invalid timestamp")
+	errSignatureTimestampExpired = htxp.RegisterCode(CodeSignatureTimestampExpired, 401, "signature.timestamp_expired", "timestamp expired")
+	errSignatureAPIKeyInvalid    = htxp.RegisterCode(CodeSignatureAPIKeyInvalid, 401, "signature.api_key_invalid", "invalid api key")
+	errSignatureInvalid          = htxp.RegisterCode(CodeSignatureInvalid, 401, "signature.invalid", "invalid signature")
+	errSignatureReplayed         = htxp.RegisterCode(CodeSignatureReplayed, 401, "signature.replayed", "replayed request")
+	errSignatureBodyReadFailed   = htxp.RegisterCode(CodeSignatureBodyReadFailed, 400, "signature.body_read_failed", "read body failed")
+	errSignatureBodyTooLarge     = htxp.RegisterCode(CodeSignatureBodyTooLarge, 413, "signature.body_too_large", "request body too large")
+)
+
+// APIKeyStore API Key密钥查询接口（可由数据库、配置或Redis实现）
+type APIKeyStore interface {
+	Secret(ctx context.Context, keyID string) (string, error)
+}
+
+// StaticAPIKeys 静态API Key映射（keyID -> secret），可直接从配置文件加载
+type StaticAPIKeys map[string]string
+
+// Secret 获取API Key对应的密钥
+func (k StaticAPIKeys) Secret(_ context.Context, keyID string) (string, error) {
+	secret, ok := k[keyID]
+	if !ok {
+		return "", ErrAPIKeyNotFound
+	}
+	return secret, nil
+}
+
+// SignatureOptions 请求签名中间件配置
+type SignatureOptions struct {
+	Cache       *htxp.CacheClient // 用于记录nonce防重放
+	KeyStore    APIKeyStore       // API Key密钥来源，Key不存在时应返回 ErrAPIKeyNotFound
+	MaxSkew     time.Duration     // 允许的时间偏差，默认 5 分钟
+	NoncePrefix string            // nonce key 前缀，默认 api:nonce
+	MaxBodySize int64             // 参与签名的最大请求体，默认 10MB
+}
+
+// SignatureMiddleware HMAC-SHA256请求签名验证中间件（服务间调用、IoT设备）
+// 签名串：METHOD\nPATH(含查询参数)\nSHA256(BODY)\nTIMESTAMP\nNONCE
+type SignatureMiddleware struct {
+	opts SignatureOptions
+}
+
+// NewSignatureMiddleware 创建请求签名验证中间件，Cache 或 KeyStore 为空时 panic
+func NewSignatureMiddleware(opts SignatureOptions) *SignatureMiddleware {
+	if opts.Cache == nil || opts.KeyStore == nil {
+		panic("htxp: signature middleware requires Cache and KeyStore")
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = 5 * time.Minute
+	}
+	if opts.NoncePrefix == "" {
+		opts.NoncePrefix = "api:nonce"
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 10 << 20
+	}
+	return &SignatureMiddleware{opts: opts}
+}
+
+// Handle 处理HTTP请求，验证请求签名
+func (m *SignatureMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keyID := r.Header.Get(HeaderAPIKey)
+		timestamp := r.Header.Get(HeaderTimestamp)
+		nonce := r.Header.Get(HeaderNonce)
+		signature := r.Header.Get(HeaderSignature)
+		if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+			htxp.ErrorCtx(r.Context(), w, errSignatureHeadersRequired)
+			return
+		}
+
+		// 1. 检查时间戳
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			htxp.ErrorCtx(r.Context(), w, errSignatureTimestampInvalid)
+			return
+		}
+		if skew := time.Since(time.Unix(ts, 0)); skew > m.opts.MaxSkew || skew < -m.opts.MaxSkew {
+			htxp.ErrorCtx(r.Context(), w, errSignatureTimestampExpired)
+			return
+		}
+
+		// 2. 查询API Key密钥（Key不存在返回401，密钥来源故障返回503）
+		secret, err := m.opts.KeyStore.Secret(r.Context(), keyID)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			htxp.ErrorCtx(r.Context(), w, errSignatureAPIKeyInvalid)
+			return
+		}
+		if err != nil {
+			logx.Errorf("查询API Key失败: keyID=%s, err=%v", keyID, err)
+			htxp.ErrorCtx(r.Context(), w, htxp.ErrServiceUnavailable)
+			return
+		}
+
+		// 3. 读取请求体并验证签名
+		body, err := io.ReadAll(io.LimitReader(r.Body, m.opts.MaxBodySize+1))
+		if err != nil {
+			htxp.ErrorCtx(r.Context(), w, errSignatureBodyReadFailed)
+			return
+		}
+		if int64(len(body)) > m.opts.MaxBodySize {
+			htxp.ErrorCtx(r.Context(), w, errSignatureBodyTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		expected := Sign(secret, StringToSign(r.Method, r.URL.RequestURI(), body, timestamp, nonce))
+		if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+			htxp.ErrorCtx(r.Context(), w, errSignatureInvalid)
+			return
+		}
+
+		// 4. 记录nonce防重放（签名通过后再写入，避免伪造请求占用nonce）
+		nonceKey := fmt.Sprintf("%s:%s:%s", m.opts.NoncePrefix, keyID, nonce)
+		ok, err := m.opts.Cache.SetNX(r.Context(), nonceKey, "1", 2*m.opts.MaxSkew).Result()
+		if err != nil {
+			logx.Errorf("记录nonce失败: %v", err)
+			htxp.ErrorCtx(r.Context(), w, htxp.ErrServiceUnavailable)
+			return
+		}
+		if !ok {
+			htxp.ErrorCtx(r.Context(), w, errSignatureReplayed)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKeyAPIKeyID, keyID)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// StringToSign 构造待签名字符串
+func StringToSign(method, requestURI string, body []byte, timestamp, nonce string) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		hex.EncodeToString(bodyHash[:]),
+		timestamp,
+		nonce,
+	}, "\n")
+}
+
+// Sign 计算HMAC-SHA256签名（小写十六进制）
+func Sign(secret, stringToSign string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest 为请求添加签名头（调用方使用）
+func SignRequest(req *http.Request, keyID, secret string) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := htxp.NewTokenID()
+	req.Header.Set(HeaderAPIKey, keyID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(secret, StringToSign(req.Method, req.URL.RequestURI(), body, timestamp, nonce)))
+	return nil
+}
+
+// APIKeyFromContext 获取请求签名使用的API Key
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(ContextKeyAPIKeyID).(string)
+	return keyID, ok
+}
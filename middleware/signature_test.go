@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/linktomarkdown/htxp"
+	"github.com/redis/go-redis/v9"
+)
+
+// apiKeyStoreFunc 测试用的 APIKeyStore
+type apiKeyStoreFunc func(ctx context.Context, keyID string) (string, error)
+
+func (f apiKeyStoreFunc) Secret(ctx context.Context, keyID string) (string, error) {
+	return f(ctx, keyID)
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := &htxp.CacheClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { _ = cache.Close() })
+	keys := StaticAPIKeys{"device": "device-secret"}
+	store := apiKeyStoreFunc(func(ctx context.Context, keyID string) (string, error) {
+		if keyID == "broken" {
+			return "", errors.New("connection refused")
+		}
+		return keys.Secret(ctx, keyID)
+	})
+	handler := NewSignatureMiddleware(SignatureOptions{Cache: cache, KeyStore: store}).Handle(
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	signed := func(keyID, secret string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/upload?x=1", strings.NewReader(`{"a":1}`))
+		if err := SignRequest(r, keyID, secret); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Body.Len() == 0 {
+			return 0
+		}
+		var body htxp.Body
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Code
+	}
+
+	valid := signed("device", "device-secret")
+	replay := httptest.NewRequest(http.MethodPost, "/upload?x=1", strings.NewReader(`{"a":1}`))
+	replay.Header = valid.Header.Clone()
+	cases := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"valid", valid, 0},
+		{"replayed", replay, CodeSignatureReplayed},
+		{"wrong secret", signed("device", "other-secret"), CodeSignatureInvalid},
+		{"unknown key", signed("unknown", "device-secret"), CodeSignatureAPIKeyInvalid},
+		{"key store error", signed("broken", "device-secret"), htxp.ErrServiceUnavailable.Code},
+		{"missing headers", httptest.NewRequest(http.MethodGet, "/", nil), CodeSignatureHeadersRequired},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if code := serve(c.req); code != c.want {
+				t.Fatalf("code = %d, want %d", code, c.want)
+			}
+		})
+	}
+}
+
+func TestSignatureMiddlewareRequiresDependencies(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("missing Cache and KeyStore accepted")
+		}
+	}()
+	NewSignatureMiddleware(SignatureOptions{})
+}
@@ -1,18 +1,43 @@
 package middleware
 
 import (
+	"context"
+	"crypto/ed25519"
 	"fmt"
 	"strconv"
 
+	"github.com/linktomarkdown/htxp"
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/redis"
 )
 
-// GetUserTokenVersion 获取用户Token版本号（从Redis）
+// TokenVersionChannel Token版本号变更通知频道（消息格式："<key> <version>"）
+const TokenVersionChannel = "user:token_version:changed"
+
+// bumpVersionScript 原子递增版本号，key不存在时视为默认版本号1
+var bumpVersionScript = redis.NewScript(`
+local v = tonumber(redis.call('GET', KEYS[1]) or '1')
+v = v + 1
+redis.call('SET', KEYS[1], v)
+return v
+`)
+
+// GetUserTokenVersion 获取用户Token版本号（从Redis），Redis不可用时返回错误
 func GetUserTokenVersion(rds *redis.Redis, userID int64) (int64, error) {
-	key := fmt.Sprintf("user:token_version:%d", userID)
-	versionStr, err := rds.Get(key)
+	return getTokenVersion(context.Background(), rds, userTokenVersionKey(userID))
+}
+
+// GetDeviceTokenVersion 获取用户某设备的Token版本号（从Redis），Redis不可用时返回错误
+func GetDeviceTokenVersion(rds *redis.Redis, userID int64, deviceID string) (int64, error) {
+	return getTokenVersion(context.Background(), rds, deviceTokenVersionKey(userID, deviceID))
+}
+
+func getTokenVersion(ctx context.Context, rds *redis.Redis, key string) (int64, error) {
+	versionStr, err := rds.GetCtx(ctx, key)
 	if err != nil {
+		return 0, err
+	}
+	if versionStr == "" {
 		// 如果不存在，返回默认版本号1（兼容旧Token）
 		return 1, nil
 	}
@@ -24,6 +49,32 @@ func GetUserTokenVersion(rds *redis.Redis, userID int64) (int64, error) {
 	return version, nil
 }
 
+// BumpUserTokenVersion 递增用户Token版本号，使该用户所有设备上的已签发Token失效
+func BumpUserTokenVersion(rds *redis.Redis, userID int64) (int64, error) {
+	return bumpTokenVersion(rds, userTokenVersionKey(userID))
+}
+
+// BumpDeviceTokenVersion 递增用户某设备的Token版本号，仅使该设备上的已签发Token失效
+func BumpDeviceTokenVersion(rds *redis.Redis, userID int64, deviceID string) (int64, error) {
+	return bumpTokenVersion(rds, deviceTokenVersionKey(userID, deviceID))
+}
+
+func bumpTokenVersion(rds *redis.Redis, key string) (int64, error) {
+	val, err := rds.ScriptRun(bumpVersionScript, []string{key})
+	if err != nil {
+		return 0, err
+	}
+	version, ok := val.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected token version: %v", val)
+	}
+	// 通知各实例刷新本地版本号缓存
+	if _, err = rds.Publish(TokenVersionChannel, fmt.Sprintf("%s %d", key, version)); err != nil {
+		logx.Errorf("发布Token版本号变更失败: %v", err)
+	}
+	return version, nil
+}
+
 // CheckTokenVersion 检查Token版本号是否有效
 func CheckTokenVersion(rds *redis.Redis, userID int64, tokenVersion int64) bool {
 	currentVersion, err := GetUserTokenVersion(rds, userID)
@@ -35,3 +86,83 @@ func CheckTokenVersion(rds *redis.Redis, userID int64, tokenVersion int64) bool
 	return tokenVersion >= currentVersion
 }
 
+// CheckClaimsVersion 检查Token的用户版本号与设备版本号是否均有效
+func CheckClaimsVersion(rds *redis.Redis, claims *JWTClaims) bool {
+	if !CheckTokenVersion(rds, claims.UserID, claims.Version) {
+		return false
+	}
+	if claims.DeviceID == "" {
+		return true
+	}
+	currentVersion, err := GetDeviceTokenVersion(rds, claims.UserID, claims.DeviceID)
+	if err != nil {
+		logx.Errorf("获取设备Token版本号失败: %v", err)
+		return false
+	}
+	return claims.DeviceVersion >= currentVersion
+}
+
+// StampTokenVersion 将用户与设备的当前版本号写入Token声明
+func StampTokenVersion(rds *redis.Redis, claims *JWTClaims) error {
+	version, err := GetUserTokenVersion(rds, claims.UserID)
+	if err != nil {
+		return err
+	}
+	claims.Version = version
+	if claims.DeviceID != "" {
+		version, err = GetDeviceTokenVersion(rds, claims.UserID, claims.DeviceID)
+		if err != nil {
+			return err
+		}
+		claims.DeviceVersion = version
+	}
+	return nil
+}
+
+// IssueToken 签发携带当前版本号的JWT（HS256，可被 ParseToken 解析）
+func IssueToken(rds *redis.Redis, secretKey string, iat, seconds int64, claims *JWTClaims) (string, error) {
+	if err := StampTokenVersion(rds, claims); err != nil {
+		return "", err
+	}
+	return htxp.GenTokenFor(secretKey, iat, seconds, claims)
+}
+
+// IssueTokenWithKeySet 使用密钥集签发携带当前版本号的JWT（可被 ParseTokenWithKeySet 解析）
+func IssueTokenWithKeySet(rds *redis.Redis, keySet *htxp.KeySet, iat, seconds int64, claims *JWTClaims) (string, error) {
+	if err := StampTokenVersion(rds, claims); err != nil {
+		return "", err
+	}
+	return htxp.GenTokenForKeySet(keySet, iat, seconds, claims)
+}
+
+// IssueEncryptedToken 签发携带当前版本号的加密JWT（HS256签名后 dir + A256GCM 加密）
+func IssueEncryptedToken(rds *redis.Redis, secretKey string, encryptionKey []byte, iat, seconds int64, claims *JWTClaims) (string, error) {
+	if err := StampTokenVersion(rds, claims); err != nil {
+		return "", err
+	}
+	return htxp.GenEncryptedToken(secretKey, encryptionKey, iat, seconds, claims)
+}
+
+// IssuePasetoLocal 签发携带当前版本号的 PASETO v4.local 令牌
+func IssuePasetoLocal(rds *redis.Redis, key []byte, iat, seconds int64, claims *JWTClaims) (string, error) {
+	if err := StampTokenVersion(rds, claims); err != nil {
+		return "", err
+	}
+	return htxp.GenPasetoLocal(key, iat, seconds, claims)
+}
+
+// IssuePasetoPublic 签发携带当前版本号的 PASETO v4.public 令牌
+func IssuePasetoPublic(rds *redis.Redis, privateKey ed25519.PrivateKey, iat, seconds int64, claims *JWTClaims) (string, error) {
+	if err := StampTokenVersion(rds, claims); err != nil {
+		return "", err
+	}
+	return htxp.GenPasetoPublic(privateKey, iat, seconds, claims)
+}
+
+func userTokenVersionKey(userID int64) string {
+	return fmt.Sprintf("user:token_version:%d", userID)
+}
+
+func deviceTokenVersionKey(userID int64, deviceID string) string {
+	return fmt.Sprintf("user:token_version:%d:%s", userID, deviceID)
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linktomarkdown/htxp"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// VersionFailurePolicy Redis不可用时的Token版本检查策略
+type VersionFailurePolicy int
+
+const (
+	// VersionFailClosed 拒绝请求（默认）
+	VersionFailClosed VersionFailurePolicy = iota
+	// VersionFailOpen 放行请求（已吊销的Token在故障期间重新生效）
+	VersionFailOpen
+	// VersionFailCached 使用本地最后已知的版本号，无缓存时拒绝请求
+	VersionFailCached
+)
+
+// TokenVersionConf Token版本检查配置
+type TokenVersionConf struct {
+	FailurePolicy VersionFailurePolicy
+	CacheTTL      time.Duration // 本地缓存有效期，0 表示每次请求都查询Redis
+	StaleTTL      time.Duration // 最后已知版本号的保留时长（VersionFailCached 使用），默认 1 小时
+}
+
+// TokenVersionChecker 带本地缓存与故障策略的Token版本检查器
+// 缓存通过 Redis pub/sub（TokenVersionChannel）失效，见 Subscribe
+type TokenVersionChecker struct {
+	redis     *redis.Redis
+	conf      TokenVersionConf
+	mu        sync.RWMutex
+	entries   map[string]versionEntry
+	lastSweep time.Time
+}
+
+type versionEntry struct {
+	version   int64
+	fetchedAt time.Time
+}
+
+// NewTokenVersionChecker 创建Token版本检查器
+func NewTokenVersionChecker(rds *redis.Redis, conf TokenVersionConf) *TokenVersionChecker {
+	if conf.StaleTTL <= 0 {
+		conf.StaleTTL = time.Hour
+	}
+	return &TokenVersionChecker{
+		redis:     rds,
+		conf:      conf,
+		entries:   make(map[string]versionEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Check 检查Token的用户版本号与设备版本号，版本过期返回 ErrTokenOutdated
+// 按故障策略无法确认版本号时返回Redis错误
+func (c *TokenVersionChecker) Check(ctx context.Context, claims *JWTClaims) error {
+	version, err := c.version(ctx, userTokenVersionKey(claims.UserID))
+	if err != nil {
+		return err
+	}
+	if claims.Version < version {
+		return ErrTokenOutdated
+	}
+	if claims.DeviceID == "" {
+		return nil
+	}
+	version, err = c.version(ctx, deviceTokenVersionKey(claims.UserID, claims.DeviceID))
+	if err != nil {
+		return err
+	}
+	if claims.DeviceVersion < version {
+		return ErrTokenOutdated
+	}
+	return nil
+}
+
+// Subscribe 订阅Token版本号变更通知以更新本地缓存，ctx 结束时退出
+func (c *TokenVersionChecker) Subscribe(ctx context.Context, client *htxp.CacheClient) {
+	pubsub := client.Subscribe(ctx, TokenVersionChannel)
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				c.apply(msg.Payload)
+			}
+		}
+	}()
+}
+
+// version 获取版本号：优先使用未过期的本地缓存，Redis失败时按策略降级
+func (c *TokenVersionChecker) version(ctx context.Context, key string) (int64, error) {
+	entry, cached := c.lookup(key)
+	if cached && c.conf.CacheTTL > 0 && time.Since(entry.fetchedAt) < c.conf.CacheTTL {
+		return entry.version, nil
+	}
+
+	version, err := getTokenVersion(ctx, c.redis, key)
+	if err == nil {
+		if c.conf.CacheTTL > 0 || c.conf.FailurePolicy == VersionFailCached {
+			c.store(key, version)
+		}
+		return version, nil
+	}
+
+	switch c.conf.FailurePolicy {
+	case VersionFailOpen:
+		logx.Errorf("获取Token版本号失败，按策略放行: key=%s, err=%v", key, err)
+		return 0, nil
+	case VersionFailCached:
+		if cached && time.Since(entry.fetchedAt) < c.conf.StaleTTL {
+			logx.Errorf("获取Token版本号失败，使用本地缓存: key=%s, err=%v", key, err)
+			return entry.version, nil
+		}
+	}
+	return 0, err
+}
+
+func (c *TokenVersionChecker) lookup(key string) (versionEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *TokenVersionChecker) store(key string, version int64) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = versionEntry{version: version, fetchedAt: now}
+	// 定期清理超过保留时长的缓存
+	if now.Sub(c.lastSweep) > c.conf.StaleTTL {
+		for k, e := range c.entries {
+			if now.Sub(e.fetchedAt) > c.conf.StaleTTL {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+}
+
+// apply 处理版本号变更通知
+func (c *TokenVersionChecker) apply(payload string) {
+	i := strings.LastIndex(payload, " ")
+	if i < 0 {
+		return
+	}
+	version, err := strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil {
+		return
+	}
+	c.store(payload[:i], version)
+}
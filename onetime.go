@@ -0,0 +1,90 @@
+package htxp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 常用的一次性令牌用途
+const (
+	PurposeEmailVerify   = "email_verify"
+	PurposePasswordReset = "password_reset"
+	PurposeInvite        = "invite"
+)
+
+// ErrOneTimeTokenInvalid 一次性令牌不存在、已过期、已使用或用途不匹配
+var ErrOneTimeTokenInvalid = errors.New("one-time token invalid")
+
+// consumeOneTimeScript 原子地读取并删除令牌（兼容不支持 GETDEL 的 Redis 版本）
+var consumeOneTimeScript = redis.NewScript(`
+local subject = redis.call('GET', KEYS[1])
+if subject then
+	redis.call('DEL', KEYS[1])
+end
+return subject
+`)
+
+// IssueOneTimeToken 签发绑定用途与主体（如用户ID、邮箱）的一次性令牌，可直接拼接到链接中
+// 令牌为22位URL安全字符串，Redis中只保存其SHA-256摘要；ttl 必须为正数，避免令牌永不过期
+func (c *CacheClient) IssueOneTimeToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	if purpose == "" || subject == "" {
+		return "", errors.New("one-time token purpose and subject required")
+	}
+	if ttl <= 0 {
+		return "", errors.New("one-time token ttl must be positive")
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if err := c.Set(ctx, oneTimeTokenKey(purpose, token), subject, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeOneTimeToken 校验用途并消费一次性令牌，返回绑定的主体；令牌仅能成功消费一次
+// 用途不匹配时令牌不会被消费（如密码重置令牌无法用于邮箱验证）
+func (c *CacheClient) ConsumeOneTimeToken(ctx context.Context, purpose, token string) (string, error) {
+	if purpose == "" || token == "" {
+		return "", ErrOneTimeTokenInvalid
+	}
+	subject, err := consumeOneTimeScript.Run(ctx, c, []string{oneTimeTokenKey(purpose, token)}).Text()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrOneTimeTokenInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+	return subject, nil
+}
+
+// PeekOneTimeToken 校验一次性令牌但不消费（如打开密码重置页面时预先校验）
+func (c *CacheClient) PeekOneTimeToken(ctx context.Context, purpose, token string) (string, error) {
+	if purpose == "" || token == "" {
+		return "", ErrOneTimeTokenInvalid
+	}
+	subject, err := c.Get(ctx, oneTimeTokenKey(purpose, token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrOneTimeTokenInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+	return subject, nil
+}
+
+// oneTimeTokenKey 用途参与key计算，不同用途的令牌互不可见
+func oneTimeTokenKey(purpose, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("onetime:%s:%s", purpose, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,298 @@
+package htxp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// PASETO v4 令牌头
+const (
+	PasetoV4Local  = "v4.local."
+	PasetoV4Public = "v4.public."
+)
+
+// PASETO v4 在算法白名单（如 middleware.ValidatorOptions.Algorithms）中的名称
+const (
+	PasetoAlgV4Local  = "v4.local"
+	PasetoAlgV4Public = "v4.public"
+)
+
+var (
+	// ErrPasetoMalformed PASETO令牌格式错误
+	ErrPasetoMalformed = errors.New("paseto token malformed")
+	// ErrPasetoInvalid PASETO令牌认证失败（密钥不匹配或内容被篡改）
+	ErrPasetoInvalid = errors.New("paseto token invalid")
+)
+
+// pasetoTimeClaims PASETO 规定 exp/iat/nbf 使用 RFC 3339 时间字符串
+var pasetoTimeClaims = []string{"exp", "iat", "nbf"}
+
+// IsPaseto 判断令牌是否为 PASETO v4 格式
+func IsPaseto(token string) bool {
+	return strings.HasPrefix(token, PasetoV4Local) || strings.HasPrefix(token, PasetoV4Public)
+}
+
+// GeneratePasetoLocalKey 生成 v4.local 对称密钥（32字节）
+func GeneratePasetoLocalKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenPasetoLocal 签发 v4.local（对称加密）令牌，声明与 GenTokenFor 一致
+func GenPasetoLocal[T TokenClaims](key []byte, iat, seconds int64, claims T) (string, error) {
+	claims.BaseClaims().Stamp(iat, seconds)
+	payload, err := MarshalPasetoClaims(claims)
+	if err != nil {
+		return "", err
+	}
+	return EncryptPasetoLocal(key, payload, nil)
+}
+
+// GenPasetoPublic 签发 v4.public（Ed25519签名）令牌，声明与 GenTokenFor 一致
+func GenPasetoPublic[T TokenClaims](privateKey ed25519.PrivateKey, iat, seconds int64, claims T) (string, error) {
+	claims.BaseClaims().Stamp(iat, seconds)
+	payload, err := MarshalPasetoClaims(claims)
+	if err != nil {
+		return "", err
+	}
+	return SignPasetoPublic(privateKey, payload, nil)
+}
+
+// ParsePasetoLocal 解密并校验 v4.local 令牌，如 htxp.ParsePasetoLocal[Claims](token, key)
+func ParsePasetoLocal[T any, PT interface {
+	*T
+	TokenClaims
+}](token string, key []byte) (PT, error) {
+	payload, _, err := DecryptPasetoLocal(key, token)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPasetoAs[T, PT](payload)
+}
+
+// ParsePasetoPublic 验签并校验 v4.public 令牌，如 htxp.ParsePasetoPublic[Claims](token, publicKey)
+func ParsePasetoPublic[T any, PT interface {
+	*T
+	TokenClaims
+}](token string, publicKey ed25519.PublicKey) (PT, error) {
+	payload, _, err := VerifyPasetoPublic(publicKey, token)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPasetoAs[T, PT](payload)
+}
+
+func unmarshalPasetoAs[T any, PT interface {
+	*T
+	TokenClaims
+}](payload []byte) (PT, error) {
+	claims := PT(new(T))
+	if err := UnmarshalPasetoClaims(payload, claims); err != nil {
+		return nil, err
+	}
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// EncryptPasetoLocal 按 v4.local 规范加密负载（XChaCha20 + BLAKE2b-MAC）
+func EncryptPasetoLocal(key, payload, footer []byte) (string, error) {
+	if len(key) != 32 {
+		return "", errors.New("paseto v4.local key must be 32 bytes")
+	}
+	n := make([]byte, 32)
+	if _, err := rand.Read(n); err != nil {
+		return "", err
+	}
+	return encryptPasetoLocal(key, n, payload, footer)
+}
+
+// encryptPasetoLocal 使用指定随机数加密，便于按官方测试向量校验
+func encryptPasetoLocal(key, n, payload, footer []byte) (string, error) {
+	ek, n2, ak := pasetoLocalKeys(key, n)
+	c := make([]byte, len(payload))
+	cipher, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return "", err
+	}
+	cipher.XORKeyStream(c, payload)
+	t := pasetoMAC(ak, pae([]byte(PasetoV4Local), n, c, footer, nil))
+
+	body := make([]byte, 0, len(n)+len(c)+len(t))
+	body = append(append(append(body, n...), c...), t...)
+	return pasetoEncode(PasetoV4Local, body, footer), nil
+}
+
+// DecryptPasetoLocal 校验并解密 v4.local 令牌，返回负载与脚注
+func DecryptPasetoLocal(key []byte, token string) (payload, footer []byte, err error) {
+	if len(key) != 32 {
+		return nil, nil, errors.New("paseto v4.local key must be 32 bytes")
+	}
+	body, footer, err := pasetoDecode(PasetoV4Local, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) < 64 {
+		return nil, nil, ErrPasetoMalformed
+	}
+	n, c, t := body[:32], body[32:len(body)-32], body[len(body)-32:]
+	ek, n2, ak := pasetoLocalKeys(key, n)
+	if subtle.ConstantTimeCompare(t, pasetoMAC(ak, pae([]byte(PasetoV4Local), n, c, footer, nil))) != 1 {
+		return nil, nil, ErrPasetoInvalid
+	}
+	cipher, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload = make([]byte, len(c))
+	cipher.XORKeyStream(payload, c)
+	return payload, footer, nil
+}
+
+// SignPasetoPublic 按 v4.public 规范签名负载（Ed25519）
+func SignPasetoPublic(privateKey ed25519.PrivateKey, payload, footer []byte) (string, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return "", errors.New("paseto v4.public key must be ed25519 private key")
+	}
+	sig := ed25519.Sign(privateKey, pae([]byte(PasetoV4Public), payload, footer, nil))
+	body := make([]byte, 0, len(payload)+len(sig))
+	body = append(append(body, payload...), sig...)
+	return pasetoEncode(PasetoV4Public, body, footer), nil
+}
+
+// VerifyPasetoPublic 验证 v4.public 令牌签名，返回负载与脚注
+func VerifyPasetoPublic(publicKey ed25519.PublicKey, token string) (payload, footer []byte, err error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, nil, errors.New("paseto v4.public key must be ed25519 public key")
+	}
+	body, footer, err := pasetoDecode(PasetoV4Public, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, nil, ErrPasetoMalformed
+	}
+	payload, sig := body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(publicKey, pae([]byte(PasetoV4Public), payload, footer, nil), sig) {
+		return nil, nil, ErrPasetoInvalid
+	}
+	return payload, footer, nil
+}
+
+// MarshalPasetoClaims 序列化声明，exp/iat/nbf 转换为 RFC 3339 时间字符串
+func MarshalPasetoClaims(claims interface{}) ([]byte, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range pasetoTimeClaims {
+		var seconds int64
+		if raw, ok := fields[name]; ok && json.Unmarshal(raw, &seconds) == nil {
+			fields[name], _ = json.Marshal(time.Unix(seconds, 0).UTC().Format(time.RFC3339))
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalPasetoClaims 反序列化声明，exp/iat/nbf 由 RFC 3339 时间字符串转换为时间戳
+func UnmarshalPasetoClaims(payload []byte, claims interface{}) error {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return ErrPasetoMalformed
+	}
+	for _, name := range pasetoTimeClaims {
+		var value string
+		if raw, ok := fields[name]; ok && json.Unmarshal(raw, &value) == nil {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return ErrPasetoMalformed
+			}
+			fields[name], _ = json.Marshal(t.Unix())
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, claims); err != nil {
+		return ErrPasetoMalformed
+	}
+	return nil
+}
+
+// pasetoLocalKeys 由主密钥与随机数派生加密密钥、XChaCha20 nonce 与认证密钥
+func pasetoLocalKeys(key, n []byte) (ek, n2, ak []byte) {
+	h, _ := blake2b.New(56, key)
+	h.Write([]byte("paseto-encryption-key"))
+	h.Write(n)
+	tmp := h.Sum(nil)
+	return tmp[:32], tmp[32:], pasetoMAC(key, append([]byte("paseto-auth-key-for-aead"), n...))
+}
+
+func pasetoMAC(key, msg []byte) []byte {
+	h, _ := blake2b.New(32, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// pae 预认证编码（Pre-Authentication Encoding）
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	le64 := func(n int) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n)&^(1<<63))
+		buf.Write(b[:])
+	}
+	le64(len(pieces))
+	for _, piece := range pieces {
+		le64(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
+
+func pasetoEncode(header string, body, footer []byte) string {
+	token := header + base64.RawURLEncoding.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+func pasetoDecode(header, token string) (body, footer []byte, err error) {
+	if !strings.HasPrefix(token, header) {
+		return nil, nil, ErrPasetoMalformed
+	}
+	parts := strings.Split(token[len(header):], ".")
+	if len(parts) > 2 {
+		return nil, nil, ErrPasetoMalformed
+	}
+	if body, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+		return nil, nil, ErrPasetoMalformed
+	}
+	if len(parts) == 2 {
+		if footer, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			return nil, nil, ErrPasetoMalformed
+		}
+	}
+	return body, footer, nil
+}
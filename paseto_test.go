@@ -0,0 +1,230 @@
+package htxp
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 官方测试向量：https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+const (
+	pasetoVectorLocalKey  = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	pasetoVectorSecretKey = "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	pasetoVectorPublicKey = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+)
+
+var pasetoLocalVectors = []struct {
+	name    string
+	nonce   string
+	payload string
+	token   string
+}{
+	{
+		name:    "4-E-1",
+		nonce:   "0000000000000000000000000000000000000000000000000000000000000000",
+		payload: `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`,
+		token: "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74Mmc" +
+			"UE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg",
+	},
+	{
+		name:    "4-E-2",
+		nonce:   "0000000000000000000000000000000000000000000000000000000000000000",
+		payload: `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`,
+		token: "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74Mmc" +
+			"UE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A",
+	},
+	{
+		name:    "4-E-3",
+		nonce:   "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8",
+		payload: `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`,
+		token: "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdo" +
+			"Hjd5-RHCiExR1IK6t6-tyebyWG6Ov7kKvBdkrrAJ837lKP3iDag2hzUPHuMKA",
+	},
+	{
+		name:    "4-E-4",
+		nonce:   "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8",
+		payload: `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`,
+		token: "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdo" +
+			"Hjd5-RHCiExR1IK6t4gt6TiLm55vIH8c_lGxxZpE3AWlH4WTR0v45nsWoU3gQ",
+	},
+}
+
+var pasetoPublicVectors = []struct {
+	name    string
+	payload string
+	footer  string
+	token   string
+}{
+	{
+		name:    "4-S-1",
+		payload: `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`,
+		token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKp" +
+			"LT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+	},
+	{
+		name:    "4-S-2",
+		payload: `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`,
+		footer:  `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`,
+		token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh" +
+			"4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMM" +
+			"Vc2MGhhTiJ9",
+	},
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPasetoLocalVectors(t *testing.T) {
+	key := mustHex(t, pasetoVectorLocalKey)
+	for _, v := range pasetoLocalVectors {
+		t.Run(v.name, func(t *testing.T) {
+			token, err := encryptPasetoLocal(key, mustHex(t, v.nonce), []byte(v.payload), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != v.token {
+				t.Fatalf("encrypt = %s, want %s", token, v.token)
+			}
+			payload, footer, err := DecryptPasetoLocal(key, v.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(payload) != v.payload || len(footer) != 0 {
+				t.Fatalf("decrypt = %q %q", payload, footer)
+			}
+		})
+	}
+}
+
+func TestPasetoPublicVectors(t *testing.T) {
+	privateKey := ed25519.PrivateKey(mustHex(t, pasetoVectorSecretKey))
+	publicKey := ed25519.PublicKey(mustHex(t, pasetoVectorPublicKey))
+	for _, v := range pasetoPublicVectors {
+		t.Run(v.name, func(t *testing.T) {
+			var footer []byte
+			if v.footer != "" {
+				footer = []byte(v.footer)
+			}
+			token, err := SignPasetoPublic(privateKey, []byte(v.payload), footer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != v.token {
+				t.Fatalf("sign = %s, want %s", token, v.token)
+			}
+			payload, gotFooter, err := VerifyPasetoPublic(publicKey, v.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(payload) != v.payload || string(gotFooter) != v.footer {
+				t.Fatalf("verify = %q %q", payload, gotFooter)
+			}
+		})
+	}
+}
+
+func TestPasetoLocalRejects(t *testing.T) {
+	key := mustHex(t, pasetoVectorLocalKey)
+	token := pasetoLocalVectors[2].token
+	wrongKey := append([]byte{}, key...)
+	wrongKey[0] ^= 1
+
+	cases := map[string]struct {
+		key   []byte
+		token string
+		want  error
+	}{
+		"wrong key":       {wrongKey, token, ErrPasetoInvalid},
+		"tampered":        {key, tamperPaseto(token, len(PasetoV4Local)+50), ErrPasetoInvalid},
+		"tampered nonce":  {key, tamperPaseto(token, len(PasetoV4Local)+1), ErrPasetoInvalid},
+		"truncated":       {key, token[:len(token)-4], ErrPasetoInvalid},
+		"too short":       {key, PasetoV4Local + "AAAA", ErrPasetoMalformed},
+		"bad base64":      {key, PasetoV4Local + "!!!!", ErrPasetoMalformed},
+		"public header":   {key, PasetoV4Public + token[len(PasetoV4Local):], ErrPasetoMalformed},
+		"unexpected part": {key, token + ".e30.e30", ErrPasetoMalformed},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := DecryptPasetoLocal(c.key, c.token); !errors.Is(err, c.want) {
+				t.Fatalf("err = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestPasetoPublicRejects(t *testing.T) {
+	publicKey := ed25519.PublicKey(mustHex(t, pasetoVectorPublicKey))
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := pasetoPublicVectors[1].token
+	body, _, _ := strings.Cut(token, ".eyJraWQ")
+
+	cases := map[string]struct {
+		key   ed25519.PublicKey
+		token string
+		want  error
+	}{
+		"wrong key":       {otherKey, token, ErrPasetoInvalid},
+		"tampered":        {publicKey, tamperPaseto(token, len(PasetoV4Public)+10), ErrPasetoInvalid},
+		"footer stripped": {publicKey, body, ErrPasetoInvalid},
+		"truncated":       {publicKey, PasetoV4Public + "AAAA", ErrPasetoMalformed},
+		"local header":    {publicKey, PasetoV4Local + token[len(PasetoV4Public):], ErrPasetoMalformed},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := VerifyPasetoPublic(c.key, c.token); !errors.Is(err, c.want) {
+				t.Fatalf("err = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestPasetoClaimsRoundTrip(t *testing.T) {
+	key, err := GeneratePasetoLocalKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iat := time.Now().Unix()
+	token, err := GenPasetoLocal(key, iat, 60, &Claims{UserID: 42, Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParsePasetoLocal[Claims](token, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 42 || claims.ExpiresAt == nil || claims.ExpiresAt.Unix() != iat+60 {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	expired, err := GenPasetoLocal(key, iat-120, 60, &Claims{UserID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ParsePasetoLocal[Claims](expired, key); err == nil {
+		t.Fatal("expired token accepted")
+	}
+}
+
+// tamperPaseto 翻转令牌第 i 个字符，保持 base64url 字符集
+func tamperPaseto(token string, i int) string {
+	b := []byte(token)
+	if b[i] == 'A' {
+		b[i] = 'B'
+	} else {
+		b[i] = 'A'
+	}
+	return string(b)
+}
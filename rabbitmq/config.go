@@ -1,6 +1,10 @@
 package rabbitmq
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/linktomarkdown/htxp"
+)
 
 type RabbitConf struct {
 	Username string
@@ -53,3 +57,12 @@ func GetRabbitURL(rabbitConf RabbitConf) string {
 	return fmt.Sprintf("amqp://%s:%s@%s:%d/%s", rabbitConf.Username, rabbitConf.Password,
 		rabbitConf.Host, rabbitConf.Port, rabbitConf.VHost)
 }
+
+// TenantRoutingKey 为路由键添加租户前缀，如 1001.order.created（topic交换机可用 1001.# 按租户订阅）
+// 租户ID不合法（如包含 . * #）时返回 htxp.ErrTenantIDInvalid
+func TenantRoutingKey(tenantID, routeKey string) (string, error) {
+	if !htxp.ValidTenantID(tenantID) {
+		return "", htxp.ErrTenantIDInvalid
+	}
+	return tenantID + "." + routeKey, nil
+}
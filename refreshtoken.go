@@ -0,0 +1,252 @@
+package htxp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrRefreshTokenInvalid 刷新令牌不存在或已过期
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	// ErrRefreshTokenRevoked 刷新令牌所属的令牌族已被吊销
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	// ErrRefreshTokenReused 刷新令牌被重复使用（整个令牌族已被吊销）
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrRefreshTokenDeviceMismatch 刷新令牌与设备不匹配
+	ErrRefreshTokenDeviceMismatch = errors.New("refresh token device mismatch")
+)
+
+// RefreshTokenOptions 刷新令牌存储配置
+type RefreshTokenOptions struct {
+	Prefix string        // Redis key 前缀，默认 refresh_token
+	TTL    time.Duration // 刷新令牌有效期，默认 30 天
+}
+
+// RefreshToken 刷新令牌信息
+type RefreshToken struct {
+	Token     string    `json:"token,omitempty"`
+	UserID    int64     `json:"userId"`
+	DeviceID  string    `json:"deviceId"`
+	FamilyID  string    `json:"familyId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RefreshTokenStore 基于Redis的刷新令牌存储
+// 令牌绑定用户与设备，每次刷新一次性轮换；已轮换的令牌再次出现时吊销整个令牌族
+type RefreshTokenStore struct {
+	cache  *CacheClient
+	prefix string
+	ttl    time.Duration
+}
+
+// rotateScript 原子地校验旧令牌并签发新令牌
+// KEYS[1] 旧令牌key, KEYS[2] 新令牌key, KEYS[3] 用户令牌族集合key
+// ARGV[1] 令牌族key前缀, ARGV[2] 设备ID, ARGV[3] 有效期（秒）, ARGV[4] 过期时间戳
+var rotateScript = redis.NewScript(`
+local data = redis.call('HMGET', KEYS[1], 'uid', 'device', 'family', 'used')
+if not data[1] then
+	return {'invalid'}
+end
+local familyKey = ARGV[1] .. data[3]
+if redis.call('HGET', familyKey, 'revoked') ~= '0' then
+	return {'revoked'}
+end
+if data[4] == '1' then
+	redis.call('HSET', familyKey, 'revoked', '1')
+	return {'reused', data[1], data[2], data[3]}
+end
+if data[2] ~= ARGV[2] then
+	return {'device'}
+end
+redis.call('HSET', KEYS[1], 'used', '1')
+redis.call('HSET', KEYS[2], 'uid', data[1], 'device', data[2], 'family', data[3], 'used', '0', 'exp', ARGV[4])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('EXPIRE', familyKey, ARGV[3])
+redis.call('EXPIRE', KEYS[3], ARGV[3])
+return {'ok', data[1], data[2], data[3]}
+`)
+
+// revokeFamilyScript 仅在令牌族存在时标记吊销，避免写入无过期时间的key
+var revokeFamilyScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], 'revoked', '1')
+end
+return 1
+`)
+
+// NewRefreshTokenStore 创建刷新令牌存储
+func NewRefreshTokenStore(cache *CacheClient, opts RefreshTokenOptions) *RefreshTokenStore {
+	if opts.Prefix == "" {
+		opts.Prefix = "refresh_token"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 30 * 24 * time.Hour
+	}
+	return &RefreshTokenStore{
+		cache:  cache,
+		prefix: opts.Prefix,
+		ttl:    opts.TTL,
+	}
+}
+
+// Issue 为用户设备签发新的刷新令牌（开启新的令牌族，用于登录）
+func (s *RefreshTokenStore) Issue(ctx context.Context, userID int64, deviceID string) (*RefreshToken, error) {
+	token, err := GenRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	familyID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	expiresAt := time.Now().Add(s.ttl)
+	uid := strconv.FormatInt(userID, 10)
+
+	_, err = s.cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.tokenKey(token), "uid", uid, "device", deviceID, "family", familyID, "used", "0", "exp", expiresAt.Unix())
+		pipe.Expire(ctx, s.tokenKey(token), s.ttl)
+		pipe.HSet(ctx, s.familyKey(familyID), "uid", uid, "device", deviceID, "revoked", "0")
+		pipe.Expire(ctx, s.familyKey(familyID), s.ttl)
+		pipe.SAdd(ctx, s.userKey(userID), familyID)
+		pipe.Expire(ctx, s.userKey(userID), s.ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RefreshToken{
+		Token:     token,
+		UserID:    userID,
+		DeviceID:  deviceID,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Rotate 使用刷新令牌换取新的刷新令牌，旧令牌立即失效
+// 旧令牌被重复使用时吊销整个令牌族并返回 ErrRefreshTokenReused
+func (s *RefreshTokenStore) Rotate(ctx context.Context, token, deviceID string) (*RefreshToken, error) {
+	newToken, err := GenRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	// 令牌所属用户不会变化，先读出以便将用户集合key作为脚本的KEYS传入
+	uid, err := s.cache.HGet(ctx, s.tokenKey(token), "uid").Int64()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.ttl)
+
+	res, err := rotateScript.Run(ctx, s.cache,
+		[]string{s.tokenKey(token), s.tokenKey(newToken), s.userKey(uid)},
+		s.familyKey(""), deviceID, int64(s.ttl/time.Second), expiresAt.Unix(),
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	switch res[0] {
+	case "ok":
+	case "reused":
+		return nil, ErrRefreshTokenReused
+	case "revoked":
+		return nil, ErrRefreshTokenRevoked
+	case "device":
+		return nil, ErrRefreshTokenDeviceMismatch
+	default:
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	userID, _ := strconv.ParseInt(res[1], 10, 64)
+	return &RefreshToken{
+		Token:     newToken,
+		UserID:    userID,
+		DeviceID:  res[2],
+		FamilyID:  res[3],
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Inspect 查询刷新令牌信息（不轮换、不产生副作用）
+func (s *RefreshTokenStore) Inspect(ctx context.Context, token string) (*RefreshToken, error) {
+	data, err := s.cache.HGetAll(ctx, s.tokenKey(token)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if data["used"] == "1" {
+		return nil, ErrRefreshTokenReused
+	}
+	revoked, err := s.cache.HGet(ctx, s.familyKey(data["family"]), "revoked").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if revoked != "0" {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	userID, _ := strconv.ParseInt(data["uid"], 10, 64)
+	exp, _ := strconv.ParseInt(data["exp"], 10, 64)
+	return &RefreshToken{
+		UserID:    userID,
+		DeviceID:  data["device"],
+		FamilyID:  data["family"],
+		ExpiresAt: time.Unix(exp, 0),
+	}, nil
+}
+
+// Revoke 吊销刷新令牌所属的整个令牌族（用于退出登录）
+func (s *RefreshTokenStore) Revoke(ctx context.Context, token string) error {
+	familyID, err := s.cache.HGet(ctx, s.tokenKey(token), "family").Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.RevokeFamily(ctx, familyID)
+}
+
+// RevokeFamily 吊销令牌族
+func (s *RefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	return revokeFamilyScript.Run(ctx, s.cache, []string{s.familyKey(familyID)}).Err()
+}
+
+// RevokeUser 吊销用户在所有设备上的刷新令牌
+func (s *RefreshTokenStore) RevokeUser(ctx context.Context, userID int64) error {
+	families, err := s.cache.SMembers(ctx, s.userKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, familyID := range families {
+		if err = s.RevokeFamily(ctx, familyID); err != nil {
+			return err
+		}
+	}
+	return s.cache.Del(ctx, s.userKey(userID)).Err()
+}
+
+// tokenKey 令牌只以SHA-256摘要形式落入Redis
+func (s *RefreshTokenStore) tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%s:token:%s", s.prefix, hex.EncodeToString(sum[:]))
+}
+
+func (s *RefreshTokenStore) familyKey(familyID string) string {
+	return fmt.Sprintf("%s:family:%s", s.prefix, familyID)
+}
+
+func (s *RefreshTokenStore) userKey(userID int64) string {
+	return fmt.Sprintf("%s:user:%d", s.prefix, userID)
+}
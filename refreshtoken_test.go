@@ -0,0 +1,92 @@
+package htxp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRefreshTokenStore(t *testing.T) (*RefreshTokenStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cache := &CacheClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { _ = cache.Close() })
+	return NewRefreshTokenStore(cache, RefreshTokenOptions{TTL: time.Hour}), mr
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	store, mr := newTestRefreshTokenStore(t)
+	ctx := context.Background()
+
+	issued, err := store.Issue(ctx, 42, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 轮换前让用户集合接近过期，轮换后应续期
+	mr.SetTTL(store.userKey(42), time.Minute)
+
+	if _, err = store.Rotate(ctx, issued.Token, "laptop"); !errors.Is(err, ErrRefreshTokenDeviceMismatch) {
+		t.Fatalf("rotate on another device: err = %v", err)
+	}
+	rotated, err := store.Rotate(ctx, issued.Token, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.UserID != 42 || rotated.DeviceID != "phone" || rotated.FamilyID != issued.FamilyID || rotated.Token == issued.Token {
+		t.Fatalf("rotated = %+v", rotated)
+	}
+	if ttl := mr.TTL(store.userKey(42)); ttl != time.Hour {
+		t.Fatalf("user set ttl = %v, want %v", ttl, time.Hour)
+	}
+	if _, err = store.Inspect(ctx, rotated.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已轮换的令牌再次使用：吊销整个令牌族
+	if _, err = store.Rotate(ctx, issued.Token, "phone"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: err = %v", err)
+	}
+	if _, err = store.Rotate(ctx, rotated.Token, "phone"); !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("rotate after reuse: err = %v", err)
+	}
+	if _, err = store.Rotate(ctx, "unknown", "phone"); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("unknown token: err = %v", err)
+	}
+}
+
+func TestRefreshTokenRevokeUser(t *testing.T) {
+	store, mr := newTestRefreshTokenStore(t)
+	ctx := context.Background()
+
+	phone, err := store.Issue(ctx, 42, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	laptop, err := store.Issue(ctx, 42, "laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.Issue(ctx, 7, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.RevokeUser(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{phone.Token, laptop.Token} {
+		if _, err = store.Rotate(ctx, token, "phone"); !errors.Is(err, ErrRefreshTokenRevoked) {
+			t.Fatalf("rotate after RevokeUser: err = %v", err)
+		}
+	}
+	if mr.Exists(store.userKey(42)) {
+		t.Fatal("user set not removed")
+	}
+	if _, err = store.Rotate(ctx, other.Token, "phone"); err != nil {
+		t.Fatalf("other user affected: %v", err)
+	}
+}
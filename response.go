@@ -1,7 +1,10 @@
 package htxp
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
@@ -13,31 +16,122 @@ type Body struct {
 	Data interface{} `json:"data,omitempty"`
 }
 
-// Success 处理成功响应
+// StatusMode HTTP状态码模式
+type StatusMode int32
+
+const (
+	// StatusAlwaysOK 始终返回HTTP 200，错误只体现在 Body.Code（默认，兼容现有客户端）
+	StatusAlwaysOK StatusMode = iota
+	// StatusFromCode 按业务码写入对应的HTTP状态码
+	StatusFromCode
+)
+
+var statusMode atomic.Int32
+
+// SetStatusMode 设置全局HTTP状态码模式，应在服务启动时调用
+func SetStatusMode(mode StatusMode) {
+	statusMode.Store(int32(mode))
+}
+
+// GetStatusMode 获取全局HTTP状态码模式
+func GetStatusMode() StatusMode {
+	return StatusMode(statusMode.Load())
+}
+
+// Success 处理成功响应（不进行语言协商，需要多语言消息时使用 SuccessCtx）
 func Success(w http.ResponseWriter, data interface{}) {
 	Response(w, data, nil, 200)
 }
 
-// Error 处理错误响应（默认 code -1）
+// Error 处理错误响应（默认 code -1，CodedError 使用其业务码）
+// 不进行语言协商，CodedError 返回默认消息；需要多语言消息时使用 ErrorCtx(r.Context(), w, err)
 func Error(w http.ResponseWriter, err error) {
 	Response(w, nil, err, -1)
 }
 
-// ErrorWithCode 支持自定义 code
+// ErrorWithCode 支持自定义 code（不进行语言协商，需要多语言消息时使用 ErrorWithCodeCtx）
 func ErrorWithCode(w http.ResponseWriter, err error, code int) {
 	Response(w, nil, err, code)
 }
 
-// Response 统一处理HTTP响应
+// SuccessCtx 处理成功响应（context 版本）
+func SuccessCtx(ctx context.Context, w http.ResponseWriter, data interface{}) {
+	ResponseCtx(ctx, w, data, nil, 200)
+}
+
+// ErrorCtx 处理错误响应，CodedError 的消息使用context中的语言（见 WithLocale）
+func ErrorCtx(ctx context.Context, w http.ResponseWriter, err error) {
+	ResponseCtx(ctx, w, nil, err, -1)
+}
+
+// ErrorWithCodeCtx 支持自定义 code（context 版本）
+func ErrorWithCodeCtx(ctx context.Context, w http.ResponseWriter, err error, code int) {
+	ResponseCtx(ctx, w, nil, err, code)
+}
+
+// Response 统一处理HTTP响应，HTTP状态码由全局模式决定（见 SetStatusMode）
+// err 链中包含 CodedError 时，以其业务码、消息与附加信息为准
+// 没有请求context，不进行语言协商；需要多语言消息时使用 ResponseCtx(r.Context(), ...)
 func Response(w http.ResponseWriter, resp interface{}, err error, code int) {
+	writeResponse(context.Background(), w, resp, err, code, GetStatusMode())
+}
+
+// ResponseCtx 统一处理HTTP响应，CodedError 的消息按context中的语言翻译
+func ResponseCtx(ctx context.Context, w http.ResponseWriter, resp interface{}, err error, code int) {
+	writeResponse(ctx, w, resp, err, code, GetStatusMode())
+}
+
+// ResponseWithMode 使用指定的HTTP状态码模式处理响应（不受全局模式影响，不进行语言协商）
+func ResponseWithMode(w http.ResponseWriter, resp interface{}, err error, code int, mode StatusMode) {
+	writeResponse(context.Background(), w, resp, err, code, mode)
+}
+
+// ResponseWithModeCtx 使用指定的HTTP状态码模式处理响应，CodedError 的消息按context中的语言翻译
+func ResponseWithModeCtx(ctx context.Context, w http.ResponseWriter, resp interface{}, err error, code int, mode StatusMode) {
+	writeResponse(ctx, w, resp, err, code, mode)
+}
+
+func writeResponse(ctx context.Context, w http.ResponseWriter, resp interface{}, err error, code int, mode StatusMode) {
 	var body Body
-	if err != nil {
+	status := http.StatusOK
+	var codedErr *CodedError
+	if errors.As(err, &codedErr) {
+		body.Code = codedErr.Code
+		body.Msg = codedErr.Message(LocaleFromContext(ctx))
+		body.Data = codedErr.Details
+		status = codedErr.Status
+		if status == 0 {
+			status = HTTPStatus(codedErr.Code)
+		}
+	} else if err != nil {
 		body.Code = code
 		body.Msg = err.Error()
+		status = HTTPStatus(code)
 	} else {
 		body.Code = code
 		body.Msg = "success"
 		body.Data = resp
 	}
-	httpx.OkJson(w, body)
+
+	if mode == StatusFromCode {
+		httpx.WriteJsonCtx(ctx, w, status, body)
+		return
+	}
+	httpx.OkJsonCtx(ctx, w, body)
+}
+
+// HTTPStatus 错误业务码对应的HTTP状态码
+// 依次使用：已注册业务码的状态码、业务码本身（400~599）、子码逐位截短后的前缀（如 40103 -> 401、4010301 -> 401），其余为 500
+func HTTPStatus(code int) int {
+	if e, ok := LookupCode(code); ok && e.Status != 0 {
+		return e.Status
+	}
+	for code > 599 {
+		code /= 10
+	}
+	// 1xx~3xx 不能表示错误，同样按 500 处理
+	if code >= 400 {
+		return code
+	}
+	return http.StatusInternalServerError
 }
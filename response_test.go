@@ -0,0 +1,45 @@
+package htxp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		want int
+	}{
+		{401, http.StatusUnauthorized},
+		{404, http.StatusNotFound},
+		{40103, http.StatusUnauthorized},
+		{40301, http.StatusForbidden},
+		{50301, http.StatusServiceUnavailable},
+		{4010301, http.StatusUnauthorized},
+		{10001, http.StatusInternalServerError},
+		{200, http.StatusInternalServerError},
+		{0, http.StatusInternalServerError},
+		{-1, http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		if got := HTTPStatus(c.code); got != c.want {
+			t.Errorf("HTTPStatus(%d) = %d, want %d", c.code, got, c.want)
+		}
+	}
+}
+
+func TestResponseWithModeStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	ResponseWithMode(w, nil, errors.New("token expired"), 40103, StatusFromCode)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w = httptest.NewRecorder()
+	ResponseWithMode(w, nil, errors.New("token expired"), 40103, StatusAlwaysOK)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -8,6 +8,15 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// 短信发送错误
+var (
+	ErrSmsMobilesRequired = RegisterCode(40001, 400, "sms.mobiles_required", "mobile numbers required")
+	ErrSmsParamsInvalid   = RegisterCode(40002, 400, "sms.params_invalid", "invalid sms parameters")
+	ErrSmsClientFailed    = RegisterCode(50001, 500, "sms.client_failed", "create sms client failed")
+	ErrSmsSendFailed      = RegisterCode(50201, 502, "sms.send_failed", "send sms failed")
+	ErrSmsRejected        = RegisterCode(50202, 502, "sms.rejected", "sms rejected by provider")
+)
+
 type AliyunSmsClient struct {
 	AccessKeyId     string
 	AccessKeySecret string
@@ -41,8 +50,8 @@ func NewAliyunSmsClient(accessKeyId, accessKeySecret, signName string) *AliyunSm
 // 发送短信
 func (c *AliyunSmsClient) SendSms(mobiles []string, templateCode string, parameters map[string]string) (*SmsResponse, error) {
 	if len(mobiles) == 0 {
-		logx.Errorf("手机号列表不能为空")
-		return nil, errors.New("手机号列表不能为空")
+		logx.Errorf("mobile numbers required")
+		return nil, ErrSmsMobilesRequired
 	}
 
 	// 阿里云短信服务一次只能发送给一个手机号
@@ -52,14 +61,14 @@ func (c *AliyunSmsClient) SendSms(mobiles []string, templateCode string, paramet
 	templateParam, err := json.Marshal(parameters)
 	if err != nil {
 		logx.Errorf("参数序列化失败: %v", err)
-		return nil, errors.New("参数序列化失败")
+		return nil, ErrSmsParamsInvalid.Wrap(err)
 	}
 
 	// 创建阿里云短信客户端
 	client, err := dysmsapi.NewClientWithAccessKey(c.RegionId, c.AccessKeyId, c.AccessKeySecret)
 	if err != nil {
 		logx.Errorf("创建阿里云客户端失败: %v", err)
-		return nil, errors.New("创建阿里云客户端失败")
+		return nil, ErrSmsClientFailed.Wrap(err)
 	}
 
 	// 创建发送短信请求
@@ -78,7 +87,7 @@ func (c *AliyunSmsClient) SendSms(mobiles []string, templateCode string, paramet
 	response, err := client.SendSms(request)
 	if err != nil {
 		logx.Errorf("发送短信失败: %v", err)
-		return nil, errors.New("发送短信失败")
+		return nil, ErrSmsSendFailed.Wrap(err)
 	}
 
 	// 打印响应信息用于调试
@@ -97,5 +106,5 @@ func (c *AliyunSmsClient) SendSms(mobiles []string, templateCode string, paramet
 		return smsResp, nil
 	}
 	logx.Errorf("短信发送失败: %s", response.Message)
-	return smsResp, errors.New("短信发送失败")
+	return smsResp, ErrSmsRejected.Wrap(errors.New(response.Message))
 }
@@ -0,0 +1,34 @@
+package htxp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrTenantIDInvalid 租户ID为空或包含非法字符
+var ErrTenantIDInvalid = errors.New("tenant id invalid")
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidTenantID 检查租户ID是否合法（字母、数字、下划线、连字符），避免租户ID中的分隔符打破隔离
+func ValidTenantID(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
+
+// TenantKey 为Redis key添加租户前缀，如 tenant:1001:user:token_version:1，租户ID不合法时返回 ErrTenantIDInvalid
+func TenantKey(tenantID, key string) (string, error) {
+	if !ValidTenantID(tenantID) {
+		return "", ErrTenantIDInvalid
+	}
+	return fmt.Sprintf("tenant:%s:%s", tenantID, key), nil
+}
+
+// TenantObjectPath 为Minio对象路径添加租户前缀，如 1001/avatar/a.png，租户ID不合法时返回 ErrTenantIDInvalid
+func TenantObjectPath(tenantID, objectName string) (string, error) {
+	if !ValidTenantID(tenantID) {
+		return "", ErrTenantIDInvalid
+	}
+	return tenantID + "/" + strings.TrimPrefix(objectName, "/"), nil
+}
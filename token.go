@@ -3,8 +3,10 @@ package htxp
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type JWTToken struct {
@@ -14,17 +16,54 @@ func NewJWTTokenLogic() *JWTToken {
 	return &JWTToken{}
 }
 
+// TokenOptions Token签发选项
+type TokenOptions struct {
+	Issuer   string   // iss：签发方（应用标识）
+	Audience []string // aud：受众（允许使用该Token的应用）
+}
+
+// DefaultTokenOptions GenToken 等函数使用的默认签发选项（服务启动时设置）
+// 设置后签发的Token只能被校验相同 iss/aud 的应用接受
+var DefaultTokenOptions TokenOptions
+
+// Stamp 将签发方与受众写入声明（声明中已存在时不覆盖）
+func (o TokenOptions) Stamp(claims jwt.MapClaims) {
+	if _, ok := claims["iss"]; !ok && o.Issuer != "" {
+		claims["iss"] = o.Issuer
+	}
+	if _, ok := claims["aud"]; !ok && len(o.Audience) > 0 {
+		claims["aud"] = o.Audience
+	}
+}
+
 // GenToken 生成jwt（包级函数，无需声明实例）
 func GenToken(secretKey string, iat, seconds int64, claims jwt.MapClaims) (string, error) {
+	return GenTokenWithOptions(secretKey, iat, seconds, claims, DefaultTokenOptions)
+}
+
+// GenTokenWithOptions 生成jwt并写入指定的签发方与受众
+func GenTokenWithOptions(secretKey string, iat, seconds int64, claims jwt.MapClaims, opts TokenOptions) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, stampClaims(claims, iat, seconds, opts))
+	return token.SignedString([]byte(secretKey))
+}
+
+// stampClaims 写入 exp、iat、jti（已存在时保留）以及签发方与受众
+func stampClaims(claims jwt.MapClaims, iat, seconds int64, opts TokenOptions) jwt.MapClaims {
 	if claims == nil {
 		claims = make(jwt.MapClaims)
 	}
 	claims["exp"] = iat + seconds
 	claims["iat"] = iat
+	if _, ok := claims["jti"]; !ok {
+		claims["jti"] = NewTokenID()
+	}
+	opts.Stamp(claims)
+	return claims
+}
 
-	token := jwt.New(jwt.SigningMethodHS256)
-	token.Claims = claims
-	return token.SignedString([]byte(secretKey))
+// NewTokenID 生成Token唯一标识（jti），用于单个Token的吊销
+func NewTokenID() string {
+	return uuid.New().String()
 }
 
 // GenTokenWithPayload 生成jwt（兼容旧版本，payload作为字符串）
@@ -42,6 +81,7 @@ func GenTokenWithUser(secretKey string, iat, seconds int64, uid, cid interface{}
 	}
 	if cid != nil {
 		claims["cid"] = cid
+		claims["tenant_id"] = fmt.Sprint(cid)
 	}
 	return GenToken(secretKey, iat, seconds, claims)
 }